@@ -201,3 +201,28 @@ func (c *Client) CreateAndVerifyAddressWithContext(ctx context.Context, in *Addr
 	out = response.Address
 	return
 }
+
+// IterateAddresses returns a ListIterator over the addresses matching the given options, whose items are of type
+// *Address.
+func (c *Client) IterateAddresses(opts *ListOptions) *ListIterator {
+	return c.IterateAddressesWithContext(context.Background(), opts)
+}
+
+// IterateAddressesWithContext performs the same operation as IterateAddresses, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateAddressesWithContext(ctx context.Context, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "addresses", copyListOptions(opts))
+}
+
+// listAddressesPage fetches a page of addresses for a ListIterator.
+func listAddressesPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	result, err := c.ListAddressesWithContext(ctx, pageListOptions(options.(*ListOptions), beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, address := range result.Addresses {
+		page.add(address, address.ID)
+	}
+	return page, nil
+}
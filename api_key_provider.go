@@ -0,0 +1,116 @@
+package easypost
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKeyProvider supplies the API key of a Client (via its APIKeyProvider property). It is consulted for every API call,
+// so keys can be rotated without rebuilding clients. Implementations must be safe for concurrent use.
+type APIKeyProvider interface {
+	// GetAPIKey returns the API key to use for a call made with the given context.
+	GetAPIKey(ctx context.Context) (string, error)
+}
+
+// StaticAPIKey is an APIKeyProvider always returning the same API key.
+type StaticAPIKey string
+
+// GetAPIKey returns the API key.
+func (k StaticAPIKey) GetAPIKey(_ context.Context) (string, error) {
+	return string(k), nil
+}
+
+// EnvAPIKey is an APIKeyProvider returning the value of the environment variable with the given name, read on every
+// call.
+type EnvAPIKey string
+
+// GetAPIKey returns the value of the environment variable, with surrounding whitespace removed.
+func (k EnvAPIKey) GetAPIKey(_ context.Context) (string, error) {
+	return strings.TrimSpace(os.Getenv(string(k))), nil
+}
+
+// FileAPIKey is an APIKeyProvider returning the content of a file, e.g. a mounted secret. The file is read again
+// whenever its modification time or size changes.
+type FileAPIKey struct {
+	path string
+
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	key     string
+}
+
+// NewFileAPIKey returns a FileAPIKey reading the API key from the file at the given path.
+func NewFileAPIKey(path string) *FileAPIKey {
+	return &FileAPIKey{path: path}
+}
+
+// GetAPIKey returns the content of the file, with surrounding whitespace removed.
+func (k *FileAPIKey) GetAPIKey(_ context.Context) (string, error) {
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return "", err
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if k.key != "" && info.ModTime().Equal(k.modTime) && info.Size() == k.size {
+		return k.key, nil
+	}
+	content, err := ioutil.ReadFile(k.path)
+	if err != nil {
+		return "", err
+	}
+	k.key = strings.TrimSpace(string(content))
+	k.modTime = info.ModTime()
+	k.size = info.Size()
+	return k.key, nil
+}
+
+// apiKey returns the API key for a call made with the given context: the one set with CallAPIKey, or else the one
+// supplied by the APIKeyProvider, or else the APIKey of the Client.
+func (c *Client) apiKey(ctx context.Context) (string, error) {
+	if options, ok := callOptionsFromContext(ctx); ok && options.apiKey != "" {
+		return options.apiKey, nil
+	}
+	if c.APIKeyProvider != nil {
+		return c.APIKeyProvider.GetAPIKey(ctx)
+	}
+	return c.APIKey, nil
+}
+
+// fallbackRequest returns a copy of a request that was rejected as unauthorized, authenticated with the key supplied
+// by the FallbackAPIKeyProvider. It returns false if there is no fallback key, or if the request used an API key set
+// with CallAPIKey, which must not be replaced.
+func (c *Client) fallbackRequest(req *http.Request) (*http.Request, bool) {
+	if c.FallbackAPIKeyProvider == nil {
+		return nil, false
+	}
+	if options, ok := callOptionsFromContext(req.Context()); ok && options.apiKey != "" {
+		return nil, false
+	}
+	apiKey, err := c.FallbackAPIKeyProvider.GetAPIKey(req.Context())
+	if err != nil || apiKey == "" {
+		return nil, false
+	}
+	if primaryKey, _, _ := req.BasicAuth(); primaryKey == apiKey {
+		return nil, false
+	}
+
+	fallback := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		fallback.Body = body
+	}
+	fallback.SetBasicAuth(apiKey, "")
+	return fallback, true
+}
@@ -77,7 +77,13 @@ func (c *Client) CreateAndBuyBatch(in ...*Shipment) (out *Batch, err error) {
 // request.
 func (c *Client) CreateAndBuyBatchWithContext(ctx context.Context, in ...*Shipment) (out *Batch, err error) {
 	req := batchRequest{Batch: &Batch{Shipments: in}}
-	err = c.post(ctx, "batches/create_and_buy", req, &out)
+	purchases := make([]plannedPurchase, 0, len(in))
+	for _, shipment := range in {
+		if shipment != nil {
+			purchases = append(purchases, plannedService(shipment.Rates, shipment.Carrier, shipment.Service, shipment.Insurance))
+		}
+	}
+	err = c.purchase(ctx, "batches/create_and_buy", req, &out, purchases...)
 	return
 }
 
@@ -93,7 +99,36 @@ func (c *Client) ListBatchesWithContext(ctx context.Context, opts *ListOptions)
 	return
 }
 
-// TODO: Add support for GetNextPage when the API supports it.
+// GetNextBatchPage returns the next page of batches
+func (c *Client) GetNextBatchPage(collection *ListBatchesResult) (out *ListBatchesResult, err error) {
+	return c.GetNextBatchPageWithContext(context.Background(), collection)
+}
+
+// GetNextBatchPageWithPageSize returns the next page of batches with a specific page size
+func (c *Client) GetNextBatchPageWithPageSize(collection *ListBatchesResult, pageSize int) (out *ListBatchesResult, err error) {
+	return c.GetNextBatchPageWithPageSizeWithContext(context.Background(), collection, pageSize)
+}
+
+// GetNextBatchPageWithContext performs the same operation as GetNextBatchPage, but
+// allows specifying a context that can interrupt the request.
+func (c *Client) GetNextBatchPageWithContext(ctx context.Context, collection *ListBatchesResult) (out *ListBatchesResult, err error) {
+	return c.GetNextBatchPageWithPageSizeWithContext(ctx, collection, 0)
+}
+
+// GetNextBatchPageWithPageSizeWithContext performs the same operation as GetNextBatchPageWithPageSize, but
+// allows specifying a context that can interrupt the request.
+func (c *Client) GetNextBatchPageWithPageSizeWithContext(ctx context.Context, collection *ListBatchesResult, pageSize int) (out *ListBatchesResult, err error) {
+	if len(collection.Batch) == 0 {
+		err = EndOfPaginationError
+		return
+	}
+	lastID := collection.Batch[len(collection.Batch)-1].ID
+	params, err := nextPageParameters(collection.HasMore, lastID, pageSize)
+	if err != nil {
+		return
+	}
+	return c.ListBatchesWithContext(ctx, params)
+}
 
 // AddShipmentsToBatch adds shipments to an existing batch, and returns the
 // updated batch object.
@@ -134,7 +169,7 @@ func (c *Client) BuyBatch(batchID string) (out *Batch, err error) {
 // BuyBatchWithContext performs the same operation as BuyBatch, but allows
 // specifying a context that can interrupt the request.
 func (c *Client) BuyBatchWithContext(ctx context.Context, batchID string) (out *Batch, err error) {
-	err = c.post(ctx, "batches/"+batchID+"/buy", nil, &out)
+	err = c.purchase(ctx, "batches/"+batchID+"/buy", nil, &out, plannedPurchase{unknown: true})
 	return
 }
 
@@ -177,3 +212,28 @@ func (c *Client) CreateBatchScanFormsWithContext(ctx context.Context, batchID, f
 	err = c.do(ctx, http.MethodPost, "batches/"+batchID+"/scan_form", vals, &out)
 	return
 }
+
+// IterateBatches returns a ListIterator over the batches matching the given options, whose items are of type
+// *Batch.
+func (c *Client) IterateBatches(opts *ListOptions) *ListIterator {
+	return c.IterateBatchesWithContext(context.Background(), opts)
+}
+
+// IterateBatchesWithContext performs the same operation as IterateBatches, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateBatchesWithContext(ctx context.Context, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "batches", copyListOptions(opts))
+}
+
+// listBatchesPage fetches a page of batches for a ListIterator.
+func listBatchesPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	result, err := c.ListBatchesWithContext(ctx, pageListOptions(options.(*ListOptions), beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, batch := range result.Batch {
+		page.add(batch, batch.ID)
+	}
+	return page, nil
+}
@@ -33,7 +33,7 @@ func (c *Client) FundWalletWithContext(ctx context.Context, amount string, prior
 	}
 
 	out := PaymentMethodObject{}
-	err = c.post(ctx, endpoint+"/"+paymentMethod.ID+"/charges", wrappedParams, &out)
+	err = c.purchase(ctx, endpoint+"/"+paymentMethod.ID+"/charges", wrappedParams, &out)
 	return err
 }
 
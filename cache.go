@@ -0,0 +1,287 @@
+package easypost
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTLs are the endpoints cached by a ResponseCache created with NewResponseCache, and how long their
+// responses are kept.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"metadata/carriers": 24 * time.Hour,
+	"carrier_types":     24 * time.Hour,
+	"carrier_accounts":  10 * time.Minute,
+	"users":             10 * time.Minute,
+	"rates/{id}":        5 * time.Minute,
+}
+
+// DefaultCacheSize is the number of responses kept by the LRUCache of a ResponseCache without a Backend.
+const DefaultCacheSize = 1000
+
+// CachedResponse is a successful API response stored by a CacheBackend.
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Expires is when the response must no longer be used.
+	Expires time.Time
+}
+
+// CacheBackend stores the responses of a ResponseCache, e.g. in memory or in a cache shared by several processes.
+// Implementations must be safe for concurrent use.
+type CacheBackend interface {
+	// Get returns the response stored under the given key, if any.
+	Get(key string) (*CachedResponse, bool)
+	// Set stores a response under the given key, to be kept for at least the given time.
+	Set(key string, response *CachedResponse, ttl time.Duration)
+}
+
+// ResponseCache caches the responses of read-only API calls made by a Client (via its Cache property). Only GET
+// requests to the endpoints listed in TTLs are cached, separately for each API key, and only successful responses are
+// stored. Concurrent identical requests are collapsed into a single API call.
+//
+// Any other request made by the Client to a resource (e.g. UpdateCarrierAccount for "carrier_accounts") invalidates
+// the cached responses of that resource. Changes made by other clients are only seen once the responses expire.
+// Cached responses are served without running hooks, rate limiting or retries.
+type ResponseCache struct {
+	// TTLs maps endpoint templates, as found in the SpanAttributeEndpoint attribute of spans (e.g. "carrier_types"
+	// or "rates/{id}"), to how long their responses are cached. Other endpoints are not cached.
+	TTLs map[string]time.Duration
+	// Backend stores the cached responses. If nil, an LRUCache of DefaultCacheSize responses is used.
+	Backend CacheBackend
+
+	mutex        sync.Mutex
+	generations  map[string]uint64
+	calls        map[string]*cacheCall
+	backendOnce  sync.Once
+	defaultStore CacheBackend
+}
+
+// NewResponseCache returns a ResponseCache caching the DefaultCacheTTLs endpoints in memory.
+func NewResponseCache() *ResponseCache {
+	ttls := make(map[string]time.Duration, len(DefaultCacheTTLs))
+	for endpoint, ttl := range DefaultCacheTTLs {
+		ttls[endpoint] = ttl
+	}
+	return &ResponseCache{TTLs: ttls}
+}
+
+// cacheCall is an API call whose response is shared by concurrent identical requests.
+type cacheCall struct {
+	done     chan struct{}
+	response *CachedResponse
+	err      error
+}
+
+// Invalidate discards the cached responses of the given resource, e.g. "carrier_accounts".
+func (rc *ResponseCache) Invalidate(resource string) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if rc.generations == nil {
+		rc.generations = make(map[string]uint64)
+	}
+	// responses are stored under keys including the generation of their resource, so older ones are never read again
+	rc.generations[resource]++
+}
+
+// backend returns the CacheBackend of the cache.
+func (rc *ResponseCache) backend() CacheBackend {
+	if rc.Backend != nil {
+		return rc.Backend
+	}
+	rc.backendOnce.Do(func() {
+		rc.defaultStore = NewLRUCache(DefaultCacheSize)
+	})
+	return rc.defaultStore
+}
+
+// key returns the key of the responses of the given request in the given resource, or false if its body cannot be
+// read. The body is part of the key, as it holds the parameters of list requests.
+func (rc *ResponseCache) key(req *http.Request, resource string) (string, bool) {
+	rc.mutex.Lock()
+	generation := rc.generations[resource]
+	rc.mutex.Unlock()
+
+	body := sha256.New()
+	if req.GetBody != nil {
+		reader, err := req.GetBody()
+		if err != nil {
+			return "", false
+		}
+		_, err = io.Copy(body, reader)
+		_ = reader.Close()
+		if err != nil {
+			return "", false
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		// the body could not be read again to be sent
+		return "", false
+	}
+
+	// the API key is hashed, so that it is not stored in the backend
+	credentials := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return hex.EncodeToString(credentials[:]) + " " + strconv.FormatUint(generation, 10) + " " + req.URL.String() +
+		" " + hex.EncodeToString(body.Sum(nil)), true
+}
+
+// wrap returns a Handler serving the requests of a Client from the cache, and sending the others to next.
+func (rc *ResponseCache) wrap(c *Client, next Handler) Handler {
+	return func(req *http.Request) (*http.Response, error) {
+		resource := c.endpointOf(req)
+		if req.Method != http.MethodGet {
+			res, err := next(req)
+			rc.Invalidate(resource)
+			return res, err
+		}
+		ttl, ok := rc.TTLs[c.endpointTemplateOf(req)]
+		if !ok || ttl <= 0 {
+			return next(req)
+		}
+
+		key, ok := rc.key(req, resource)
+		if !ok {
+			return next(req)
+		}
+		if cached, ok := rc.backend().Get(key); ok && time.Now().Before(cached.Expires) {
+			return cachedHTTPResponse(req, cached), nil
+		}
+		return rc.fetch(req, key, ttl, next)
+	}
+}
+
+// fetch sends the request to next, unless an identical request is already in flight, whose response is then shared.
+func (rc *ResponseCache) fetch(req *http.Request, key string, ttl time.Duration, next Handler) (*http.Response, error) {
+	rc.mutex.Lock()
+	if call, ok := rc.calls[key]; ok {
+		rc.mutex.Unlock()
+		select {
+		case <-call.done:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		if call.err == nil {
+			return cachedHTTPResponse(req, call.response), nil
+		}
+		if !errors.Is(call.err, context.Canceled) && !errors.Is(call.err, context.DeadlineExceeded) {
+			return nil, call.err
+		}
+		// the shared call was interrupted by the context of its caller, which does not apply to this one
+		return next(req)
+	}
+	if rc.calls == nil {
+		rc.calls = make(map[string]*cacheCall)
+	}
+	call := &cacheCall{done: make(chan struct{})}
+	rc.calls[key] = call
+	rc.mutex.Unlock()
+
+	defer func() {
+		rc.mutex.Lock()
+		delete(rc.calls, key)
+		rc.mutex.Unlock()
+		close(call.done)
+	}()
+
+	res, err := next(req)
+	if err != nil {
+		call.err = err
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		call.err = err
+		return nil, err
+	}
+	call.response = &CachedResponse{StatusCode: res.StatusCode, Header: res.Header, Body: body, Expires: time.Now().Add(ttl)}
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		rc.backend().Set(key, call.response, ttl)
+	}
+	return cachedHTTPResponse(req, call.response), nil
+}
+
+// cachedHTTPResponse returns a new response to the given request with the content of a cached one.
+func cachedHTTPResponse(req *http.Request, cached *CachedResponse) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(cached.StatusCode) + " " + http.StatusText(cached.StatusCode),
+		StatusCode:    cached.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cached.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+}
+
+// LRUCache is a CacheBackend keeping a limited number of responses in memory, discarding the least recently used
+// ones first.
+type LRUCache struct {
+	size int
+
+	mutex   sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewLRUCache returns an LRUCache keeping at most size responses, or any number of them if size is not positive.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get returns the response stored under the given key, if any.
+func (l *LRUCache) Get(key string) (*CachedResponse, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry).response, true
+}
+
+// Set stores a response under the given key. Expired responses are kept until they are discarded to make room for
+// others.
+func (l *LRUCache) Set(key string, response *CachedResponse, _ time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		element.Value.(*lruEntry).response = response
+		l.order.MoveToFront(element)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, response: response})
+	for l.order.Len() > l.size && l.size > 0 {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of responses stored.
+func (l *LRUCache) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.order.Len()
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"io"
@@ -13,6 +12,7 @@ import (
 	"net/url"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -34,19 +34,37 @@ func init() {
 }
 
 // A Client provides an HTTP client for EasyPost API operations.
+//
+// A Client is safe for concurrent use by multiple goroutines, including while hooks or mock requests are added or
+// removed, as long as its fields are not modified after the first request.
 type Client struct {
 	// BaseURL specifies the location of the API. It is used with
 	// ResolveReference to create request URLs. (If 'Path' is specified, it
 	// should end with a trailing slash.) If nil, the default will be used.
 	BaseURL *url.URL
 	// Client is an HTTP client used to make API requests. If nil,
-	// http.DefaultClient will be used.
+	// http.DefaultClient will be used. It is not modified: requests are
+	// sent with a copy of it, using the Timeout of this Client, which is
+	// made when the Client is created with New, or by its first request.
 	Client *http.Client
-	// APIKey is the user's API key. It is required.
+	// APIKey is the user's API key. It is required, unless APIKeyProvider is set.
 	// Note: Treat your API Keys as passwords—keep them secret. API Keys give
 	// full read/write access to your account, so they should not be included in
 	// public repositories, emails, client side code, etc.
 	APIKey string
+	// APIKeyProvider supplies the API key for each call, e.g. to rotate keys without rebuilding the Client. If set, it
+	// takes precedence over APIKey.
+	APIKeyProvider APIKeyProvider
+	// FallbackAPIKeyProvider supplies a secondary API key. A call rejected with an UnauthorizedError is sent again once
+	// with this key, e.g. while the primary key is being rotated. If nil, there is no fallback.
+	FallbackAPIKeyProvider APIKeyProvider
+	// ExpectedMode is the mode (ModeTest or ModeProduction) the API key of this Client is expected to have. Purchases
+	// are refused with a ModeMismatchError if the key has another mode, known from VerifyMode or from the Mode field of
+	// API responses, or verified before the first purchase made with the key. If empty, modes are not checked.
+	ExpectedMode string
+	// SpendingPolicy limits the labels bought by this Client, e.g. their rate or the postage spent per day. If nil,
+	// purchases are not limited.
+	SpendingPolicy *SpendingPolicy
 	// UserAgent is a User-Agent to be sent with API HTTP requests. If empty,
 	// a default will be used.
 	UserAgent string
@@ -54,15 +72,59 @@ type Client struct {
 	// timeout includes connection time, any redirects, and reading the
 	// response body.
 	Timeout int
-	// MockRequests is a list of requests that will be mocked by the client.
+	// MockRequests is a list of requests that will be mocked by the client. It is directly accessible, but should not
+	// be modified directly once requests are made (use AddMockRequest/SetMockRequests, which are safe to call while
+	// requests are being made).
 	MockRequests []MockRequest
 	// Hooks is a collection of HookEventSubscriber instances for various hooks available in the client
 	Hooks Hooks
+	// RetryPolicy specifies how failed requests are retried. If nil, requests are attempted only once.
+	RetryPolicy *RetryPolicy
+	// RateLimiter throttles the requests made by this Client. It can be shared by several clients. If nil, requests are not throttled.
+	RateLimiter *RateLimiter
+	// Middlewares wrap every request made by this Client, the first one being the outermost (see Use).
+	Middlewares []Middleware
+	// Cache serves the responses of read-only calls, e.g. GetCarrierTypes, from a cache. It is innermost to the
+	// Middlewares. If nil, responses are not cached.
+	Cache *ResponseCache
+	// Redactor removes secrets from the headers and bodies passed to hooks and embedded in errors. If nil, the
+	// DefaultRedactor is used; set it to an empty Redactor to disable redaction.
+	Redactor *Redactor
+	// Logger receives one structured record per API call made by this Client. If nil, nothing is logged.
+	Logger Logger
+	// LogLevel is the minimum level of the records sent to the Logger. At LogLevelDebug, records also include the
+	// redacted request and response bodies.
+	LogLevel LogLevel
+	// Metrics records the request counts, latencies, errors and purchases of this Client. It can be shared by several
+	// clients. If nil, no metrics are recorded.
+	Metrics MetricsCollector
+	// Tracer starts a span for each API call made by this Client, as a child of the span in the caller's context. If
+	// nil, calls are not traced.
+	Tracer Tracer
+
+	modes      modeTracker
+	httpClient httpClient
+	// mockMutex guards MockRequests, which is replaced rather than modified in place so that requests can iterate
+	// over a snapshot without holding it
+	mockMutex sync.Mutex
 }
 
-// New returns a new Client with the given API key.
-func New(apiKey string) *Client {
-	return &Client{APIKey: apiKey}
+// httpClient is the HTTP client requests are sent with, built from the Client and Timeout of a Client.
+type httpClient struct {
+	mutex   sync.Mutex
+	source  *http.Client
+	timeout time.Duration
+	client  *http.Client
+}
+
+// New returns a new Client with the given API key, configured by the given options.
+func New(apiKey string, opts ...Option) *Client {
+	client := &Client{APIKey: apiKey}
+	for _, opt := range opts {
+		opt(client)
+	}
+	client.client()
+	return client
 }
 
 func (c *Client) baseURL() *url.URL {
@@ -88,13 +150,25 @@ func (c *Client) timeout() time.Duration {
 	return time.Duration(timeout) * time.Millisecond
 }
 
+// client returns the HTTP client used to send requests: a copy of Client (or http.DefaultClient) with the Timeout of
+// this Client. The supplied HTTP client is never modified, so it can be shared with other code. The copy is made once,
+// and again only if Client or Timeout are changed.
 func (c *Client) client() *http.Client {
-	client := c.Client
-	if client == nil {
-		client = http.DefaultClient
+	source := c.Client
+	if source == nil {
+		source = http.DefaultClient
 	}
-	client.Timeout = c.timeout()
-	return client
+	timeout := c.timeout()
+
+	c.httpClient.mutex.Lock()
+	defer c.httpClient.mutex.Unlock()
+
+	if c.httpClient.client == nil || c.httpClient.source != source || c.httpClient.timeout != timeout {
+		client := *source
+		client.Timeout = timeout
+		c.httpClient.source, c.httpClient.timeout, c.httpClient.client = source, timeout, &client
+	}
+	return c.httpClient.client
 }
 
 func (c *Client) convertOptsToURLValues(v interface{}) url.Values {
@@ -132,9 +206,16 @@ func (c *Client) setBody(req *http.Request, in interface{}) error {
 	return nil
 }
 
-func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
-	if c.APIKey == "" {
-		return newMissingPropertyError("APIKey")
+func (c *Client) newRequest(ctx context.Context, method, path string, in interface{}) (*http.Request, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == "" {
+		return nil, newMissingPropertyError("APIKey")
 	}
 
 	req := &http.Request{
@@ -157,43 +238,245 @@ func (c *Client) do(ctx context.Context, method, path string, in, out interface{
 
 	req.Header.Set("User-Agent", c.userAgent())
 	if err := c.setBody(req, in); err != nil {
+		return nil, err
+	}
+	if options, ok := callOptionsFromContext(ctx); ok {
+		for key, values := range options.header {
+			req.Header[key] = append([]string(nil), values...)
+		}
+	}
+
+	req.SetBasicAuth(apiKey, "")
+	return req.WithContext(ctx), nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, in)
+	if err != nil {
 		return err
 	}
+	return c.doRequest(req, out)
+}
+
+// callInfo holds the details of a single API call, shared by all its attempts through the request's context.
+type callInfo struct {
+	// id is shared by all attempts of the call, so hooks can correlate them
+	id                uuid.UUID
+	attempts          int
+	requestTimestamp  time.Time
+	responseTimestamp time.Time
+	// responseBody is only kept when it is logged
+	responseBody []byte
+}
+
+// timestamps returns the time the first attempt of the call was sent and the time its last response was received.
+// Missing timestamps, e.g. when the call failed before being sent, are replaced by the current time.
+func (call *callInfo) timestamps() (time.Time, time.Time) {
+	responseTimestamp := call.responseTimestamp
+	if responseTimestamp.IsZero() {
+		responseTimestamp = time.Now()
+	}
+	requestTimestamp := call.requestTimestamp
+	if requestTimestamp.IsZero() {
+		requestTimestamp = responseTimestamp
+	}
+	return requestTimestamp, responseTimestamp
+}
 
-	req.SetBasicAuth(c.APIKey, "")
-	if ctx != nil {
+type callInfoContextKey struct{}
+
+// callInfoFromContext returns the callInfo stored in ctx by doRequest, or a new one if there is none.
+func callInfoFromContext(ctx context.Context) *callInfo {
+	if call, ok := ctx.Value(callInfoContextKey{}).(*callInfo); ok {
+		return call
+	}
+	return &callInfo{id: uuid.New()}
+}
+
+// doRequest sends the request through the middleware chain and decodes a successful response into out.
+func (c *Client) doRequest(req *http.Request, out interface{}) error {
+	call := &callInfo{id: uuid.New()}
+	if options, ok := callOptionsFromContext(req.Context()); ok && options.timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), options.timeout)
+		// the response body is fully read before returning
+		defer cancel()
 		req = req.WithContext(ctx)
 	}
+	ctx, span := c.startSpan(req)
+	req = req.WithContext(context.WithValue(ctx, callInfoContextKey{}, call))
+
+	res, err := c.handler()(req)
+	if err == nil && res.StatusCode == http.StatusUnauthorized {
+		if fallback, ok := c.fallbackRequest(req); ok {
+			// the response of the primary key is discarded
+			_ = res.Body.Close()
+			// a purchase must not be made with a fallback key of another mode
+			if err = c.checkFallbackMode(fallback); err != nil {
+				res = nil
+			} else {
+				req = fallback
+				res, err = c.handler()(req)
+			}
+		}
+	}
+	if err == nil && c.logBodies() {
+		call.responseBody, err = ioutil.ReadAll(res.Body)
+		_ = res.Body.Close()
+		res.Body = ioutil.NopCloser(bytes.NewReader(call.responseBody))
+	}
+	if err == nil {
+		err = c.decodeResponse(res, out)
+	}
+	if err == nil {
+		c.recordMode(req, out)
+	}
+
+	endSpan(span, call, res, err)
+	c.logCall(req, call, res, err)
+	c.recordMetrics(req, call, res, err, out)
+	return err
+}
+
+// transportErrorReader reads the body of a response, returning the errors of the HTTP client (e.g. a timeout while
+// reading the body, or a connection reset before its end) as the errors returned when the request could not be sent.
+type transportErrorReader struct {
+	io.ReadCloser
+	req *http.Request
+}
+
+func (r *transportErrorReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = newTransportError(r.req, err)
+	}
+	return n, err
+}
+
+// decodeResponse decodes a successful response into out, or returns the APIError matching an unsuccessful one.
+func (c *Client) decodeResponse(res *http.Response, out interface{}) error {
+	defer func() { _ = res.Body.Close() }()
+
+	// status code is 2xx, no error occurred
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		if out != nil {
+			return json.NewDecoder(res.Body).Decode(out)
+		}
+		return nil
+	}
+
+	// status code is not 2xx, an error occurred
+	apiErr := buildErrorFromResponse(res, c.redactor())
+
+	return apiErr
+}
+
+// roundTrip sends the request, retrying it according to the RetryPolicy, and returns the response of the last attempt.
+// It is the innermost Handler of the middleware chain.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	call := callInfoFromContext(ctx)
+	endpoint := c.endpointOf(req)
+
+	for attempt := 1; ; attempt++ {
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx, endpoint); err != nil {
+				return nil, err
+			}
+		}
+
+		// attempts are numbered across the whole call, including those made with a fallback API key
+		call.attempts++
+		res, err := c.send(ctx, req, call)
+		if err == errNoMatchingMockRequest {
+			return nil, err
+		}
+		if _, ok := err.(*HookError); ok {
+			return nil, err
+		}
+		if err == nil && c.RateLimiter != nil {
+			c.RateLimiter.observe(endpoint, res.StatusCode)
+		}
+
+		if !c.RetryPolicy.shouldRetry(req, attempt, res, err) {
+			return res, err
+		}
+
+		delay := c.RetryPolicy.backoff(attempt, res)
+		if res != nil {
+			// the response of this attempt is discarded
+			_ = res.Body.Close()
+		}
+		if sleepErr := sleepWithContext(ctx, delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+	}
+}
+
+// send makes a single attempt of the given request and executes the request and response hooks around it.
+func (c *Client) send(ctx context.Context, req *http.Request, call *callInfo) (*http.Response, error) {
+	req = req.Clone(ctx)
 
 	var res *http.Response
 	var err error
 
 	// prepare and execute request hook(s)
-	requestId := uuid.New()
+	requestSubscriptions, responseSubscriptions := c.Hooks.subscriptions()
 	requestTimestamp := time.Now()
-	requestEvent := &RequestHookEvent{
-		Method:           req.Method,
-		Url:              req.URL,
-		RequestBody:      req.Body,
-		Headers:          req.Header,
-		RequestTimestamp: requestTimestamp,
-		Id:               requestId,
+	if call.attempts == 1 {
+		call.requestTimestamp = requestTimestamp
+	}
+	if len(requestSubscriptions) > 0 {
+		requestBody, err := readRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		requestEvent := &RequestHookEvent{
+			Method:           req.Method,
+			Url:              req.URL,
+			Headers:          c.redactor().RedactHeaders(req.Header),
+			RequestTimestamp: requestTimestamp,
+			Id:               call.id,
+			Attempt:          call.attempts,
+			IdempotencyKey:   req.Header.Get(IdempotencyKeyHeader),
+		}
+		if err := c.Hooks.fireRequestEvent(ctx, requestSubscriptions, *requestEvent, c.redactor().RedactBody(requestBody)); err != nil {
+			return nil, err
+		}
 	}
 
-	// loop over each request hook and execute it
-	for _, hook := range c.Hooks.RequestHookEventSubscriptions {
-		hook.Execute(ctx, *requestEvent)
+	if req.GetBody != nil {
+		// each attempt needs a fresh copy of the body, the previous one has been consumed
+		req.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if len(c.MockRequests) > 0 {
+	markPurchaseSent(req)
+	if mockRequests := c.mockRequests(); len(mockRequests) > 0 {
 		// If there are mock requests set, this client will ONLY make mock requests
-		res = c.findMatchingMockRequest(req)
+		res = findMatchingMockRequest(mockRequests, req)
 		if res == nil {
-			return errors.New("no matching mock request found")
+			return nil, errNoMatchingMockRequest
 		}
 	} else {
 		// Otherwise, make a real request
 		res, err = c.client().Do(req)
+		if err != nil {
+			err = newTransportError(req, err)
+		} else {
+			res.Body = &transportErrorReader{ReadCloser: res.Body, req: req}
+		}
+	}
+	if res != nil && res.Request == nil {
+		// errors built from the response need the request, which mocked responses and some transports leave out
+		res.Request = req
+	}
+	call.responseTimestamp = time.Now()
+
+	if len(responseSubscriptions) == 0 {
+		return res, err
 	}
 
 	if err != nil {
@@ -202,51 +485,55 @@ func (c *Client) do(ctx context.Context, method, path string, in, out interface{
 			HttpStatus:        0,
 			Method:            req.Method,
 			Url:               req.URL,
-			ResponseBody:      nil,
 			Headers:           nil,
 			RequestTimestamp:  requestTimestamp,
-			ResponseTimestamp: time.Now(),
-			Id:                requestId,
-		}
-		// loop over each response hook and execute it
-		for _, hook := range c.Hooks.ResponseHookEventSubscriptions {
-			hook.Execute(ctx, *responseEvent)
+			ResponseTimestamp: call.responseTimestamp,
+			Id:                call.id,
+			Attempt:           call.attempts,
 		}
+		// the transport error takes precedence over any hook error
+		_ = c.Hooks.fireResponseEvent(ctx, responseSubscriptions, *responseEvent, nil)
 
-		return err
+		return nil, err
+	}
+
+	// buffer the response body, so that hooks can read it without consuming it
+	responseBody, err := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
 	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
 
 	// prepare and execute response hook(s) for successful requests
 	responseEvent := &ResponseHookEvent{
 		HttpStatus:        res.StatusCode,
 		Method:            req.Method,
 		Url:               req.URL,
-		ResponseBody:      res.Body,
-		Headers:           res.Header,
+		Headers:           c.redactor().RedactHeaders(res.Header),
 		RequestTimestamp:  requestTimestamp,
-		ResponseTimestamp: time.Now(),
-		Id:                requestId,
+		ResponseTimestamp: call.responseTimestamp,
+		Id:                call.id,
+		Attempt:           call.attempts,
 	}
-
-	// loop over each response hook and execute it
-	for _, hook := range c.Hooks.ResponseHookEventSubscriptions {
-		hook.Execute(ctx, *responseEvent)
+	if err := c.Hooks.fireResponseEvent(ctx, responseSubscriptions, *responseEvent, c.redactor().RedactBody(responseBody)); err != nil {
+		return nil, err
 	}
 
-	defer func() { _ = res.Body.Close() }()
+	return res, nil
+}
 
-	// status code is 2xx, no error occurred
-	if res.StatusCode >= 200 && res.StatusCode <= 299 {
-		if out != nil {
-			return json.NewDecoder(res.Body).Decode(out)
-		}
-		return nil
+// readRequestBody returns a copy of the request's body, without consuming it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.GetBody == nil {
+		return nil, nil
 	}
-
-	// status code is not 2xx, an error occurred
-	apiErr := BuildErrorFromResponse(res)
-
-	return apiErr
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+	return ioutil.ReadAll(body)
 }
 
 func (c *Client) get(ctx context.Context, path string, out interface{}) error {
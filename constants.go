@@ -6,6 +6,8 @@ func getCarrierAccountTypesWithCustomWorkflows() []string {
 
 var ApiDidNotReturnErrorDetails = "API did not return error details"
 var ApiErrorDetailsParsingError = "RESPONSE.PARSE_ERROR"
+var HookExecutionFailed = "Hook execution failed: "
+var InvalidListCursor = "Invalid list cursor"
 var InvalidParameter = "Invalid parameter: "
 var JsonDeserializationErrorMessage = "Error deserializing JSON into object of type "
 var JsonNoDataErrorMessage = "No data was provided to serialize"
@@ -14,8 +16,11 @@ var MismatchWebhookSignature = "Webhook received did not originate from EasyPost
 var MissingProperty = "Missing property: "
 var MissingRequiredParameter = "Missing required parameter: "
 var MissingWebhookSignature = "Webhook does not contain a valid HMAC signature."
+var ModeMismatch = "Mode mismatch: "
 var NoMatchingPaymentMethod = "No matching payment method type found"
 var NoPagesLeftToRetrieve = "There are no more pages to retrieve"
 var NoPaymentMethods = "No payment methods are set up. Please add a payment method and try again."
 var NoRatesFoundMatchingFilters = "No rates found matching the given filters"
 var PaymentMethodNotSetUp = "The chosen payment method is not set up yet"
+var SpendingPolicyViolation = "Spending policy violation: "
+var UnknownAPIKeyMode = "The mode of the API key could not be determined"
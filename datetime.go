@@ -1,9 +1,19 @@
 package easypost
 
-import "time"
-
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DateTime is a point in time sent to or received from the API. It remembers whether it was parsed from a date-only
+// value such as the est_delivery_date of a Tracker, in which case it is marshaled back as a date; other values are
+// marshaled as RFC 3339 timestamps, keeping their time zone offset and fractional seconds.
 type DateTime time.Time
 
+// dateOnly is the location of the DateTime values parsed from a date-only value, at midnight UTC.
+var dateOnly = time.FixedZone("UTC", 0)
+
 func (dt *DateTime) UnmarshalJSON(b []byte) (err error) {
 	// if string is empty, return nil
 	if string(b) == "null" {
@@ -16,7 +26,7 @@ func (dt *DateTime) UnmarshalJSON(b []byte) (err error) {
 	// 2006-01-02
 	t, err = time.Parse(`"2006-01-02"`, string(b))
 	if err == nil {
-		*dt = DateTime(t)
+		*dt = DateTime(t.In(dateOnly))
 		return
 	}
 
@@ -94,17 +104,52 @@ func (dt *DateTime) UnmarshalJSON(b []byte) (err error) {
 }
 
 func (dt DateTime) MarshalJSON() ([]byte, error) {
+	if dt.IsDateOnly() {
+		return dt.Date().MarshalJSON()
+	}
 	return time.Time(dt).MarshalJSON()
 }
 
 func (dt DateTime) String() string {
+	if dt.IsDateOnly() {
+		return dt.Date().String()
+	}
 	return time.Time(dt).String()
 }
 
 func (dt DateTime) AsTime() time.Time {
+	if dt.IsDateOnly() {
+		return time.Time(dt).UTC()
+	}
 	return time.Time(dt)
 }
 
+// IsDateOnly reports whether the DateTime was parsed from a date-only value, or created with Date.AsDateTime.
+func (dt DateTime) IsDateOnly() bool {
+	return time.Time(dt).Location() == dateOnly
+}
+
+// IsZero reports whether the DateTime is the zero time, January 1, year 1, 00:00:00 UTC.
+func (dt DateTime) IsZero() bool {
+	return time.Time(dt).IsZero()
+}
+
+// InLocation returns the same instant as the DateTime, in the given location, e.g. to display a timestamp in the
+// time zone of an address. A date-only DateTime has no time zone, and is returned unchanged.
+//
+// InLocation panics if loc is nil.
+func (dt DateTime) InLocation(loc *time.Location) DateTime {
+	if dt.IsDateOnly() {
+		return dt
+	}
+	return DateTime(time.Time(dt).In(loc))
+}
+
+// Date returns the calendar date of the DateTime, in its own location.
+func (dt DateTime) Date() Date {
+	return DateFromTime(time.Time(dt))
+}
+
 // NewDateTime returns the DateTime corresponding to
 //
 //	yyyy-mm-dd hh:mm:ss + nsec nanoseconds
@@ -132,3 +177,75 @@ func NewDateTime(year int, month time.Month, day, hour, min, sec, nsec int, loc
 func DateTimeFromTime(t time.Time) DateTime {
 	return DateTime(t)
 }
+
+// EncodeValues encodes the DateTime as an RFC 3339 timestamp into URL query parameters (e.g. the StartDateTime of
+// ListOptions), keeping its time zone offset and fractional seconds. It implements the query.Encoder interface of
+// go-querystring.
+func (dt DateTime) EncodeValues(key string, v *url.Values) error {
+	v.Set(key, time.Time(dt).Format(time.RFC3339Nano))
+	return nil
+}
+
+// Date is a calendar date without a time of day or time zone, sent to and received from the API as "2006-01-02",
+// e.g. the StartDate of a Report.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the Date of the given year, month and day. They are normalized like the arguments of time.Date,
+// e.g. October 32 converts to November 1.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateFromTime(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateFromTime returns the calendar date of a time.Time, in its own location.
+func DateFromTime(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// UnmarshalJSON parses a date, or the date of any timestamp accepted by DateTime in its own time zone offset, as the
+// label_date of a PostageLabel is sent as a full timestamp.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var dt DateTime
+	if err := dt.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	*d = dt.Date()
+	return nil
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero reports whether the Date is the zero value.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// AsTime returns midnight UTC at the start of the Date.
+func (d Date) AsTime() time.Time {
+	return d.In(time.UTC)
+}
+
+// In returns midnight at the start of the Date in the given location.
+//
+// In panics if loc is nil.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AsDateTime returns the Date as a date-only DateTime, marshaled as a date.
+func (d Date) AsDateTime() DateTime {
+	return DateTime(d.AsTime().In(dateOnly))
+}
@@ -50,7 +50,36 @@ func (c *Client) ListEndShippersWithContext(ctx context.Context, opts *ListOptio
 	return
 }
 
-// TODO: Add support for GetNextPage when the API supports it.
+// GetNextEndShipperPage returns the next page of end shippers
+func (c *Client) GetNextEndShipperPage(collection *ListEndShipperResult) (out *ListEndShipperResult, err error) {
+	return c.GetNextEndShipperPageWithContext(context.Background(), collection)
+}
+
+// GetNextEndShipperPageWithPageSize returns the next page of end shippers with a specific page size
+func (c *Client) GetNextEndShipperPageWithPageSize(collection *ListEndShipperResult, pageSize int) (out *ListEndShipperResult, err error) {
+	return c.GetNextEndShipperPageWithPageSizeWithContext(context.Background(), collection, pageSize)
+}
+
+// GetNextEndShipperPageWithContext performs the same operation as GetNextEndShipperPage, but
+// allows specifying a context that can interrupt the request.
+func (c *Client) GetNextEndShipperPageWithContext(ctx context.Context, collection *ListEndShipperResult) (out *ListEndShipperResult, err error) {
+	return c.GetNextEndShipperPageWithPageSizeWithContext(ctx, collection, 0)
+}
+
+// GetNextEndShipperPageWithPageSizeWithContext performs the same operation as GetNextEndShipperPageWithPageSize, but
+// allows specifying a context that can interrupt the request.
+func (c *Client) GetNextEndShipperPageWithPageSizeWithContext(ctx context.Context, collection *ListEndShipperResult, pageSize int) (out *ListEndShipperResult, err error) {
+	if len(collection.EndShippers) == 0 {
+		err = EndOfPaginationError
+		return
+	}
+	lastID := collection.EndShippers[len(collection.EndShippers)-1].ID
+	params, err := nextPageParameters(collection.HasMore, lastID, pageSize)
+	if err != nil {
+		return
+	}
+	return c.ListEndShippersWithContext(ctx, params)
+}
 
 // UpdateEndShippers updates previously created endshipper
 func (c *Client) UpdateEndShippers(in *Address) (out *Address, err error) {
@@ -67,3 +96,28 @@ func (c *Client) UpdateEndShippersWithContext(ctx context.Context, in *Address)
 	err = c.do(ctx, http.MethodPut, "end_shippers/"+in.ID, wrappedParams, &out)
 	return
 }
+
+// IterateEndShippers returns a ListIterator over the end shippers matching the given options, whose items are of type
+// *Address.
+func (c *Client) IterateEndShippers(opts *ListOptions) *ListIterator {
+	return c.IterateEndShippersWithContext(context.Background(), opts)
+}
+
+// IterateEndShippersWithContext performs the same operation as IterateEndShippers, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateEndShippersWithContext(ctx context.Context, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "end_shippers", copyListOptions(opts))
+}
+
+// listEndShippersPage fetches a page of end shippers for a ListIterator.
+func listEndShippersPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	result, err := c.ListEndShippersWithContext(ctx, pageListOptions(options.(*ListOptions), beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, endShipper := range result.EndShippers {
+		page.add(endShipper, endShipper.ID)
+	}
+	return page, nil
+}
@@ -1,11 +1,18 @@
 package easypost
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 // Error represents an Error object returned by the EasyPost API.
@@ -128,6 +135,60 @@ func newMissingPropertyError(property string) *MissingPropertyError {
 	return &MissingPropertyError{LocalError{LibraryError{Message: message}}}
 }
 
+// ModeMismatchError is raised when a purchase is attempted with an API key, or on objects, whose mode (test or
+// production) differs from the ExpectedMode of the Client. No request is sent.
+type ModeMismatchError struct {
+	LocalError
+	// ExpectedMode is the ExpectedMode of the Client.
+	ExpectedMode string
+	// ActualMode is the mode of the API key or object.
+	ActualMode string
+}
+
+// newModeMismatchError returns a new ModeMismatchError object with the given modes.
+func newModeMismatchError(expectedMode, actualMode string) *ModeMismatchError {
+	return &ModeMismatchError{
+		LocalError:   LocalError{LibraryError{Message: ModeMismatch + "expected " + expectedMode + ", got " + actualMode}},
+		ExpectedMode: expectedMode,
+		ActualMode:   actualMode,
+	}
+}
+
+// SpendingPolicyError is raised when a purchase violates the SpendingPolicy of the Client. No request is sent.
+type SpendingPolicyError struct {
+	LocalError
+	// Rule is the rule of the policy that the purchase violates.
+	Rule SpendingRule
+}
+
+// newSpendingPolicyError returns a new SpendingPolicyError object for the given rule, with the given details.
+func newSpendingPolicyError(rule SpendingRule, details string) *SpendingPolicyError {
+	return &SpendingPolicyError{
+		LocalError: LocalError{LibraryError{Message: SpendingPolicyViolation + details}},
+		Rule:       rule,
+	}
+}
+
+// HookError is raised when a hook callback returns an error and the Hooks' ErrorPolicy is AbortOnHookErrors.
+type HookError struct {
+	LocalError
+	// HookID is the ID of the subscriber whose callback failed.
+	HookID string
+	// Err is the error returned by the callback.
+	Err error
+}
+
+// newHookError returns a new HookError object wrapping the given error of the given subscriber.
+func newHookError(hookID string, err error) *HookError {
+	message := fmt.Sprintf("%s%s: %v", HookExecutionFailed, hookID, err)
+	return &HookError{LocalError: LocalError{LibraryError{Message: message}}, HookID: hookID, Err: err}
+}
+
+// Unwrap returns the error returned by the hook callback.
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
 // MissingWebhookSignatureError is raised when a webhook does not contain a valid HMAC signature.
 var MissingWebhookSignatureError = &LocalError{LibraryError{Message: MissingWebhookSignature}}
 
@@ -146,6 +207,30 @@ func newExternalApiError(message string) *ExternalApiError {
 
 // API/HTTP error types
 
+// Sentinel errors matching the API/HTTP error types with errors.Is, e.g. errors.Is(err, ErrNotFound) is true if err is
+// (or wraps) a NotFoundError. ErrAPI matches all of them.
+var (
+	ErrAPI                = errors.New("easypost: API error")
+	ErrBadRequest         = errors.New("easypost: bad request")
+	ErrConnection         = errors.New("easypost: connection error")
+	ErrGatewayTimeout     = errors.New("easypost: gateway timeout")
+	ErrInternalServer     = errors.New("easypost: internal server error")
+	ErrInvalidRequest     = errors.New("easypost: invalid request")
+	ErrMethodNotAllowed   = errors.New("easypost: method not allowed")
+	ErrNotFound           = errors.New("easypost: not found")
+	ErrPayment            = errors.New("easypost: payment required")
+	ErrProxy              = errors.New("easypost: proxy error")
+	ErrRateLimit          = errors.New("easypost: rate limit exceeded")
+	ErrRedirect           = errors.New("easypost: redirect")
+	ErrRetry              = errors.New("easypost: informational response")
+	ErrServiceUnavailable = errors.New("easypost: service unavailable")
+	ErrSSL                = errors.New("easypost: SSL error")
+	ErrTimeout            = errors.New("easypost: timeout")
+	ErrUnauthorized       = errors.New("easypost: unauthorized")
+	ErrForbidden          = errors.New("easypost: forbidden")
+	ErrUnknownHttp        = errors.New("easypost: unknown HTTP error")
+)
+
 // APIError represents an error that occurred while communicating with the EasyPost API.
 //
 // This is typically due to a specific HTTP status code, such as 4xx or 5xx.
@@ -161,6 +246,23 @@ type APIError struct {
 	StatusCode int
 	// Errors may be provided if there are details about server-side issues that caused the API request to fail.
 	Errors []*Error `json:"errors,omitempty"`
+	// Err is the underlying cause of the error, such as the network error of a ConnectionError. It is nil for errors
+	// built from an API response.
+	Err error `json:"-"`
+	// Method is the HTTP method of the request that failed.
+	Method string
+	// Path is the URL path of the request that failed.
+	Path string
+	// RequestID is the ID generated by the client for the API call, shared by all the hook events of its attempts
+	// (see RequestHookEvent.Id). It is the zero UUID if the request was not made by a Client.
+	RequestID uuid.UUID
+	// ServerRequestID is the ID assigned to the request by the API (the X-Ep-Request-Uuid response header), which
+	// EasyPost support can use to look up the request.
+	ServerRequestID string
+	// Headers are the headers of the response, with secrets redacted.
+	Headers http.Header
+	// Body is the raw body of the response, with secrets redacted.
+	Body []byte
 }
 
 // Error provides a pretty printed string of an APIError object based on present data.
@@ -177,111 +279,387 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("%d %s", e.StatusCode, e.Code)
 }
 
+// Unwrap returns the underlying cause of the error, if any.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is ErrAPI.
+func (e *APIError) Is(target error) bool {
+	return target == ErrAPI
+}
+
+// retryableErrorCodes are the EasyPost error codes reporting a transient condition, whatever the status code.
+var retryableErrorCodes = map[ErrorCode]bool{
+	ErrorCodeRateLimited:        true,
+	ErrorCodeServiceUnavailable: true,
+	ErrorCodeServiceTimeout:     true,
+}
+
+// Retryable returns true if the request that failed can safely be sent again as-is, because the API did not process
+// it due to a transient condition: a connection failure or timeout, a 408, 429, 502, 503 or 504 status code, or an
+// EasyPost error code reporting a transient condition. Requests that are not idempotent should only be retried if
+// they carry an idempotency key.
+func (e *APIError) Retryable() bool {
+	if retryableErrorCodes[e.ErrorCode()] {
+		return true
+	}
+	switch e.StatusCode {
+	case 0, http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Temporary returns true if the error is caused by a condition that is expected to go away on its own. This includes
+// all retryable errors, as well as 500 status codes, which may be transient but may also have been raised after the
+// request was processed.
+func (e *APIError) Temporary() bool {
+	return e.Retryable() || e.StatusCode == http.StatusInternalServerError
+}
+
+// apiError returns the APIError itself. It is promoted to all the error types embedding an APIError.
+func (e *APIError) apiError() *APIError {
+	return e
+}
+
+// apiErrorer is implemented by APIError and all the error types embedding it.
+type apiErrorer interface {
+	apiError() *APIError
+}
+
 // BadRequestError is raised when the API returns a 400 status code.
 type BadRequestError struct {
 	APIError
 }
 
-// ConnectionError is raised when the API returns a 0 status code.
+// Is returns true if target is ErrBadRequest or ErrAPI.
+func (e *BadRequestError) Is(target error) bool {
+	return target == ErrBadRequest || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *BadRequestError) Unwrap() error {
+	return &e.APIError
+}
+
+// ConnectionError is raised when the API returns a 0 status code, or when the request could not be sent or its
+// response could not be received (e.g. DNS or dial failures, connection resets).
 type ConnectionError struct {
 	APIError
 }
 
+// Is returns true if target is ErrConnection or ErrAPI.
+func (e *ConnectionError) Is(target error) bool {
+	return target == ErrConnection || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *ConnectionError) Unwrap() error {
+	return &e.APIError
+}
+
 // GatewayTimeoutError is raised when the API returns a 504 status code.
 type GatewayTimeoutError struct {
 	APIError
 }
 
+// Is returns true if target is ErrGatewayTimeout or ErrAPI.
+func (e *GatewayTimeoutError) Is(target error) bool {
+	return target == ErrGatewayTimeout || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *GatewayTimeoutError) Unwrap() error {
+	return &e.APIError
+}
+
 // InternalServerError is raised when the API returns a 500 status code.
 type InternalServerError struct {
 	APIError
 }
 
+// Is returns true if target is ErrInternalServer or ErrAPI.
+func (e *InternalServerError) Is(target error) bool {
+	return target == ErrInternalServer || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *InternalServerError) Unwrap() error {
+	return &e.APIError
+}
+
 // InvalidRequestError is raised when the API returns a 422 status code.
 type InvalidRequestError struct {
 	APIError
 }
 
+// Is returns true if target is ErrInvalidRequest or ErrAPI.
+func (e *InvalidRequestError) Is(target error) bool {
+	return target == ErrInvalidRequest || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *InvalidRequestError) Unwrap() error {
+	return &e.APIError
+}
+
 // MethodNotAllowedError is raised when the API returns a 405 status code.
 type MethodNotAllowedError struct {
 	APIError
 }
 
+// Is returns true if target is ErrMethodNotAllowed or ErrAPI.
+func (e *MethodNotAllowedError) Is(target error) bool {
+	return target == ErrMethodNotAllowed || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *MethodNotAllowedError) Unwrap() error {
+	return &e.APIError
+}
+
 // NotFoundError is raised when the API returns a 404 status code.
 type NotFoundError struct {
 	APIError
 }
 
+// Is returns true if target is ErrNotFound or ErrAPI.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *NotFoundError) Unwrap() error {
+	return &e.APIError
+}
+
 // PaymentError is raised when the API returns a 402 status code.
 type PaymentError struct {
 	APIError
 }
 
-// ProxyError is raised when the API returns a 407 status code.
+// Is returns true if target is ErrPayment or ErrAPI.
+func (e *PaymentError) Is(target error) bool {
+	return target == ErrPayment || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *PaymentError) Unwrap() error {
+	return &e.APIError
+}
+
+// ProxyError is raised when the API returns a 407 status code, or when connecting through a proxy failed.
 type ProxyError struct {
 	APIError
 }
 
+// Is returns true if target is ErrProxy or ErrAPI.
+func (e *ProxyError) Is(target error) bool {
+	return target == ErrProxy || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *ProxyError) Unwrap() error {
+	return &e.APIError
+}
+
+// Retryable returns false, proxy failures require a configuration change.
+func (e *ProxyError) Retryable() bool {
+	return false
+}
+
+// Temporary returns false, proxy failures require a configuration change.
+func (e *ProxyError) Temporary() bool {
+	return false
+}
+
 // RateLimitError is raised when the API returns a 429 status code.
 type RateLimitError struct {
 	APIError
 }
 
+// Is returns true if target is ErrRateLimit or ErrAPI.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimit || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *RateLimitError) Unwrap() error {
+	return &e.APIError
+}
+
 // RedirectError is raised when the API returns a 3xx status code.
 type RedirectError struct {
 	APIError
 }
 
+// Is returns true if target is ErrRedirect or ErrAPI.
+func (e *RedirectError) Is(target error) bool {
+	return target == ErrRedirect || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *RedirectError) Unwrap() error {
+	return &e.APIError
+}
+
 // RetryError is raised when the API returns a 1xx status code.
 type RetryError struct {
 	APIError
 }
 
+// Is returns true if target is ErrRetry or ErrAPI.
+func (e *RetryError) Is(target error) bool {
+	return target == ErrRetry || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *RetryError) Unwrap() error {
+	return &e.APIError
+}
+
 // ServiceUnavailableError is raised when the API returns a 503 status code.
 type ServiceUnavailableError struct {
 	APIError
 }
 
-// SSLError is raised when there is an issue with the SSL certificate.
+// Is returns true if target is ErrServiceUnavailable or ErrAPI.
+func (e *ServiceUnavailableError) Is(target error) bool {
+	return target == ErrServiceUnavailable || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *ServiceUnavailableError) Unwrap() error {
+	return &e.APIError
+}
+
+// SSLError is raised when there is an issue with the SSL certificate, or the TLS handshake failed.
 type SSLError struct {
 	APIError
 }
 
-// TimeoutError is raised when the API returns a 408 status code.
+// Is returns true if target is ErrSSL or ErrAPI.
+func (e *SSLError) Is(target error) bool {
+	return target == ErrSSL || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *SSLError) Unwrap() error {
+	return &e.APIError
+}
+
+// Retryable returns false, certificate and handshake failures require a configuration change.
+func (e *SSLError) Retryable() bool {
+	return false
+}
+
+// Temporary returns false, certificate and handshake failures require a configuration change.
+func (e *SSLError) Temporary() bool {
+	return false
+}
+
+// TimeoutError is raised when the API returns a 408 status code, or when the request timed out (including when the
+// deadline of its context was exceeded).
 type TimeoutError struct {
 	APIError
 }
 
+// Is returns true if target is ErrTimeout or ErrAPI.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *TimeoutError) Unwrap() error {
+	return &e.APIError
+}
+
 // UnauthorizedError is raised when the API returns a 401 status code.
 type UnauthorizedError struct {
 	APIError
 }
 
+// Is returns true if target is ErrUnauthorized or ErrAPI.
+func (e *UnauthorizedError) Is(target error) bool {
+	return target == ErrUnauthorized || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *UnauthorizedError) Unwrap() error {
+	return &e.APIError
+}
+
 // ForbiddenError is raised when the API returns a 403 status code.
 type ForbiddenError struct {
 	APIError
 }
 
+// Is returns true if target is ErrForbidden or ErrAPI.
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *ForbiddenError) Unwrap() error {
+	return &e.APIError
+}
+
 // UnknownHttpError is raised when the API returns an unrecognized status code.
 type UnknownHttpError struct {
 	APIError
 }
 
+// Is returns true if target is ErrUnknownHttp or ErrAPI.
+func (e *UnknownHttpError) Is(target error) bool {
+	return target == ErrUnknownHttp || target == ErrAPI
+}
+
+// Unwrap returns the embedded APIError.
+func (e *UnknownHttpError) Unwrap() error {
+	return &e.APIError
+}
+
 // BuildErrorFromResponse returns an APIError-based object based on the HTTP response.
 // Do not pass a non-error response to this function.
+//
+// Secrets in the response headers and body embedded in the error are redacted with the DefaultRedactor.
 func BuildErrorFromResponse(response *http.Response) error {
+	return buildErrorFromResponse(response, defaultRedactor)
+}
+
+// ServerRequestIDHeader is the response header carrying the ID assigned to each request by the API.
+const ServerRequestIDHeader = "X-Ep-Request-Uuid"
+
+// buildErrorFromResponse returns an APIError-based object based on the HTTP response, redacting the response headers
+// and body embedded in the error with the given Redactor.
+func buildErrorFromResponse(response *http.Response, redactor *Redactor) error {
 	// build the base APIError object from the response
 	apiError := &APIError{
-		StatusCode: response.StatusCode,
+		StatusCode:      response.StatusCode,
+		ServerRequestID: response.Header.Get(ServerRequestIDHeader),
+		Headers:         redactor.RedactHeaders(response.Header),
+	}
+	if request := response.Request; request != nil {
+		apiError.Method = request.Method
+		if request.URL != nil {
+			apiError.Path = request.URL.Path
+		}
+		if call, ok := request.Context().Value(callInfoContextKey{}).(*callInfo); ok {
+			apiError.RequestID = call.id
+		}
 	}
 
 	// deserialize the response body into a temporary object
 	buf, _ := ioutil.ReadAll(response.Body)
+	apiError.Body = redactor.RedactBody(buf)
 	tmpError := &struct {
 		Error *Error `json:"error,omitempty"`
 	}{}
 
-	if json.Unmarshal(buf, &tmpError) == nil {
+	if json.Unmarshal(buf, &tmpError) == nil && tmpError.Error != nil {
 		// extract the details from the temporary object (top-level Error class) and store them in the APIError object
 		apiError.Message = tmpError.Error.Message.(string)
 		apiError.Code = tmpError.Error.Code
@@ -334,3 +712,51 @@ func BuildErrorFromResponse(response *http.Response) error {
 		return &UnknownHttpError{APIError: *apiError}
 	}
 }
+
+// newTransportError returns the ConnectionError, TimeoutError, SSLError or ProxyError matching an error returned by the
+// HTTP client when the given request could not be completed. The original error is kept as the cause, so it can be retrieved
+// with errors.Unwrap, errors.Is or errors.As. Errors caused by the cancellation of the request's context are returned
+// unchanged.
+func newTransportError(req *http.Request, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	apiError := APIError{
+		LibraryError: LibraryError{Message: err.Error()},
+		Err:          err,
+		Method:       req.Method,
+		Path:         req.URL.Path,
+	}
+	if call, ok := req.Context().Value(callInfoContextKey{}).(*callInfo); ok {
+		apiError.RequestID = call.id
+	}
+
+	var opError *net.OpError
+	isOpError := errors.As(err, &opError)
+	var netError net.Error
+	switch {
+	case isOpError && opError.Op == "proxyconnect", strings.Contains(err.Error(), "proxyconnect"):
+		return &ProxyError{APIError: apiError}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netError) && netError.Timeout():
+		return &TimeoutError{APIError: apiError}
+	case isTLSError(err):
+		return &SSLError{APIError: apiError}
+	default:
+		return &ConnectionError{APIError: apiError}
+	}
+}
+
+// isTLSError returns true if the given error was caused by a failed TLS handshake or an invalid certificate.
+func isTLSError(err error) bool {
+	var unknownAuthorityError x509.UnknownAuthorityError
+	var hostnameError x509.HostnameError
+	var certificateInvalidError x509.CertificateInvalidError
+	var recordHeaderError tls.RecordHeaderError
+	switch {
+	case errors.As(err, &unknownAuthorityError), errors.As(err, &hostnameError),
+		errors.As(err, &certificateInvalidError), errors.As(err, &recordHeaderError):
+		return true
+	}
+	// other handshake failures, such as alerts sent by the server, are not exported as types
+	return strings.Contains(err.Error(), "tls: ") || strings.Contains(err.Error(), "x509: ")
+}
@@ -0,0 +1,87 @@
+package easypost
+
+// ErrorCode is a machine-readable code of a problem reported by the API, found in the Code field of Error and APIError.
+type ErrorCode string
+
+// Known error codes returned by the API. The API may return codes that are not listed here.
+const (
+	ErrorCodeAddressVerifyFailure                  ErrorCode = "ADDRESS.VERIFY.FAILURE"
+	ErrorCodeBillingInvalidPaymentGatewayReference ErrorCode = "BILLING.INVALID_PAYMENT_GATEWAY_REFERENCE"
+	ErrorCodeInternalServerError                   ErrorCode = "INTERNAL_SERVER_ERROR"
+	ErrorCodeNotFound                              ErrorCode = "NOT_FOUND"
+	ErrorCodeParameterInvalid                      ErrorCode = "PARAMETER.INVALID"
+	ErrorCodeParameterRequired                     ErrorCode = "PARAMETER.REQUIRED"
+	ErrorCodePayloadNotFound                       ErrorCode = "PAYLOAD.NOT_FOUND"
+	ErrorCodeRateLimited                           ErrorCode = "RATE_LIMITED"
+	ErrorCodeSecondaryInformationInvalid           ErrorCode = "E.SECONDARY_INFORMATION.INVALID"
+	ErrorCodeServiceTimeout                        ErrorCode = "SERVICE.TIMEOUT"
+	ErrorCodeServiceUnavailable                    ErrorCode = "SERVICE.UNAVAILABLE"
+	ErrorCodeShipmentInvalidParams                 ErrorCode = "SHIPMENT.INVALID_PARAMS"
+	ErrorCodeShipmentPostageFailure                ErrorCode = "SHIPMENT.POSTAGE.FAILURE"
+	ErrorCodeTrackerCreateError                    ErrorCode = "TRACKER.CREATE.ERROR"
+	ErrorCodeTransactionAmountInvalid              ErrorCode = "TRANSACTION.AMOUNT_INVALID"
+	ErrorCodeTransactionDoesNotExist               ErrorCode = "TRANSACTION.DOES_NOT_EXIST"
+	ErrorCodeUnprocessableEntity                   ErrorCode = "UNPROCESSABLE_ENTITY"
+	// ErrorCodeResponseParseError is set by this library when the details of an error could not be parsed from the
+	// API response.
+	ErrorCodeResponseParseError ErrorCode = "RESPONSE.PARSE_ERROR"
+)
+
+// ErrorCode returns the Code of the error as an ErrorCode.
+func (e *Error) ErrorCode() ErrorCode {
+	return ErrorCode(e.Code)
+}
+
+// ErrorCode returns the Code of the error as an ErrorCode.
+func (e *APIError) ErrorCode() ErrorCode {
+	return ErrorCode(e.Code)
+}
+
+// FieldErrors returns the errors relating to a specific field (e.g. "street1" or "weight"), keyed by field, including
+// nested errors. Errors that do not relate to a field are left out.
+func (e *APIError) FieldErrors() map[string][]*Error {
+	fieldErrors := make(map[string][]*Error)
+	collectFieldErrors(e.Errors, fieldErrors)
+	return fieldErrors
+}
+
+// FieldError returns the first error relating to the given field, if any.
+func (e *APIError) FieldError(field string) (*Error, bool) {
+	fieldErrors := e.FieldErrors()[field]
+	if len(fieldErrors) == 0 {
+		return nil, false
+	}
+	return fieldErrors[0], true
+}
+
+// FieldMessages returns the messages of the errors relating to a specific field, keyed by field. The messages of
+// several errors relating to the same field are joined with ", ".
+func (e *APIError) FieldMessages() map[string]string {
+	messages := make(map[string]string)
+	for field, fieldErrors := range e.FieldErrors() {
+		for _, fieldError := range fieldErrors {
+			message, _ := fieldError.Message.(string)
+			if message == "" {
+				continue
+			}
+			if messages[field] != "" {
+				messages[field] += ", "
+			}
+			messages[field] += message
+		}
+	}
+	return messages
+}
+
+// collectFieldErrors recursively adds the errors relating to a field to fieldErrors.
+func collectFieldErrors(errors []*Error, fieldErrors map[string][]*Error) {
+	for _, err := range errors {
+		if err == nil {
+			continue
+		}
+		if err.Field != "" {
+			fieldErrors[err.Field] = append(fieldErrors[err.Field], err)
+		}
+		collectFieldErrors(err.Errors, fieldErrors)
+	}
+}
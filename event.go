@@ -186,3 +186,28 @@ func (c *Client) GetEventPayloadWithContext(ctx context.Context, eventID, payloa
 	err = c.get(ctx, "events/"+eventID+"/payloads/"+payloadID, &out)
 	return
 }
+
+// IterateEvents returns a ListIterator over the events matching the given options, whose items are of type
+// *Event.
+func (c *Client) IterateEvents(opts *ListOptions) *ListIterator {
+	return c.IterateEventsWithContext(context.Background(), opts)
+}
+
+// IterateEventsWithContext performs the same operation as IterateEvents, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateEventsWithContext(ctx context.Context, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "events", copyListOptions(opts))
+}
+
+// listEventsPage fetches a page of events for a ListIterator.
+func listEventsPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	result, err := c.ListEventsWithContext(ctx, pageListOptions(options.(*ListOptions), beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, event := range result.Events {
+		page.add(event, event.ID)
+	}
+	return page, nil
+}
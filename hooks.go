@@ -1,10 +1,14 @@
 package easypost
 
 import (
+	"bytes"
 	"context"
 	"github.com/google/uuid"
 	"io"
+	"io/ioutil"
+	"log"
 	"net/url"
+	"sync"
 	"time"
 )
 
@@ -16,17 +20,29 @@ type HookEvent struct {
 // ID needs to be unique in order to find and remove the subscriber
 type HookEventSubscriber struct {
 	ID string // need unique ID for removal
+	// Priority determines the order in which subscribers are executed: lower values are executed first, and
+	// subscribers with the same priority are executed in the order they were added.
+	Priority int
 }
 
 // RequestHookEvent is the data passed to a RequestHookEventSubscriberCallback function
 type RequestHookEvent struct {
-	HookEvent        // implements HookEvent
-	Method           string
-	Url              *url.URL
-	RequestBody      io.ReadCloser
-	Headers          map[string][]string
-	RequestTimestamp time.Time
-	Id               uuid.UUID
+	HookEvent // implements HookEvent
+	Method    string
+	Url       *url.URL
+	// RequestBody reads the body of the request. Each subscriber gets its own reader, reading it does not affect the request.
+	RequestBody io.ReadCloser
+	// RequestBodyBytes holds the body of the request, truncated to Hooks.MaxBodySize bytes if set.
+	RequestBodyBytes []byte
+	// RequestBodyTruncated is true if RequestBodyBytes was truncated to Hooks.MaxBodySize bytes.
+	RequestBodyTruncated bool
+	Headers              map[string][]string
+	RequestTimestamp     time.Time
+	Id                   uuid.UUID
+	// Attempt is the 1-based number of the attempt this event belongs to; it is greater than 1 for retried requests.
+	Attempt int
+	// IdempotencyKey is the idempotency key sent with purchase requests (e.g. BuyShipment). It is empty for other requests.
+	IdempotencyKey string
 }
 
 // RequestHookEventSubscriberCallback is the type of the callback function executed by an RequestHookEventSubscriber
@@ -38,25 +54,37 @@ type RequestHookEventSubscriber struct {
 	Callback            RequestHookEventSubscriberCallback
 }
 
-// Execute executes the RequestHookEventSubscriberCallback function of the RequestHookEventSubscriber
+// Execute executes the RequestHookEventSubscriberCallback function of the RequestHookEventSubscriber.
+// It ignores the error returned by the callback, whatever the ErrorPolicy of the Hooks; use Call to get it.
 func (s RequestHookEventSubscriber) Execute(ctx context.Context, event RequestHookEvent) {
-	err := s.Callback(ctx, event)
-	if err != nil {
-		return
-	}
+	_ = s.Call(ctx, event)
+}
+
+// Call executes the RequestHookEventSubscriberCallback function of the RequestHookEventSubscriber, and returns its error.
+// Like Execute, it does not apply the ErrorPolicy of the Hooks, which only applies to the hooks executed by a Client.
+func (s RequestHookEventSubscriber) Call(ctx context.Context, event RequestHookEvent) error {
+	return s.Callback(ctx, event)
 }
 
 // ResponseHookEvent is the data passed to a ResponseHookEventSubscriberCallback function
 type ResponseHookEvent struct {
-	HookEvent         // implements HookEvent
-	HttpStatus        int
-	Method            string
-	Url               *url.URL
-	Headers           map[string][]string
-	ResponseBody      io.ReadCloser
-	RequestTimestamp  time.Time
-	ResponseTimestamp time.Time
-	Id                uuid.UUID
+	HookEvent  // implements HookEvent
+	HttpStatus int
+	Method     string
+	Url        *url.URL
+	Headers    map[string][]string
+	// ResponseBody reads the body of the response. Each subscriber gets its own reader, reading it does not affect
+	// the deserialization of the response.
+	ResponseBody io.ReadCloser
+	// ResponseBodyBytes holds the body of the response, truncated to Hooks.MaxBodySize bytes if set.
+	ResponseBodyBytes []byte
+	// ResponseBodyTruncated is true if ResponseBodyBytes was truncated to Hooks.MaxBodySize bytes.
+	ResponseBodyTruncated bool
+	RequestTimestamp      time.Time
+	ResponseTimestamp     time.Time
+	Id                    uuid.UUID
+	// Attempt is the 1-based number of the attempt this event belongs to; it is greater than 1 for retried requests.
+	Attempt int
 }
 
 // ResponseHookEventSubscriberCallback is the type of the callback function executed by an ResponseHookEventSubscriber
@@ -68,35 +96,273 @@ type ResponseHookEventSubscriber struct {
 	Callback            ResponseHookEventSubscriberCallback
 }
 
-// Execute executes the ResponseHookEventSubscriberCallback function of the ResponseHookEventSubscriber
+// Execute executes the ResponseHookEventSubscriberCallback function of the ResponseHookEventSubscriber.
+// It ignores the error returned by the callback, whatever the ErrorPolicy of the Hooks; use Call to get it.
 func (s ResponseHookEventSubscriber) Execute(ctx context.Context, event ResponseHookEvent) {
-	err := s.Callback(ctx, event)
-	if err != nil {
-		return
-	}
+	_ = s.Call(ctx, event)
+}
+
+// Call executes the ResponseHookEventSubscriberCallback function of the ResponseHookEventSubscriber, and returns its error.
+// Like Execute, it does not apply the ErrorPolicy of the Hooks, which only applies to the hooks executed by a Client.
+func (s ResponseHookEventSubscriber) Call(ctx context.Context, event ResponseHookEvent) error {
+	return s.Callback(ctx, event)
+}
+
+// HookErrorPolicy determines what happens when a hook callback returns an error.
+type HookErrorPolicy int
+
+const (
+	// IgnoreHookErrors discards errors returned by hook callbacks. This is the default.
+	IgnoreHookErrors HookErrorPolicy = iota
+	// LogHookErrors reports errors returned by hook callbacks to the Hooks' ErrorLogger.
+	LogHookErrors
+	// AbortOnHookErrors aborts the request when a hook callback returns an error: the Client method returns a
+	// HookError wrapping the callback's error. Errors of hooks executed asynchronously are logged instead.
+	AbortOnHookErrors
+)
+
+// HookErrorLogger is used to report errors returned by hook callbacks. It is implemented by *log.Logger.
+type HookErrorLogger interface {
+	Printf(format string, v ...interface{})
 }
 
 // Hooks is a collection of HookEventSubscriber instances for various hooks available in the client
 type Hooks struct {
-	RequestHookEventSubscriptions  []RequestHookEventSubscriber // these are directly accessible by the user, but should not be modified directly (use Add/Remove methods)
+	// these are directly accessible by the user, but should not be modified directly (use Add/Remove methods, which
+	// are safe to call while requests are being made)
+	RequestHookEventSubscriptions  []RequestHookEventSubscriber
 	ResponseHookEventSubscriptions []ResponseHookEventSubscriber
+	// MaxBodySize is the maximum number of body bytes passed to subscribers; longer bodies are truncated.
+	// This does not affect the actual request or response. If 0, bodies are passed in full.
+	MaxBodySize int
+	// ErrorPolicy determines what happens when a hook callback returns an error.
+	ErrorPolicy HookErrorPolicy
+	// ErrorLogger receives the errors of hook callbacks when ErrorPolicy is LogHookErrors (or AbortOnHookErrors for
+	// asynchronous hooks). If nil, the standard logger of the log package is used.
+	ErrorLogger HookErrorLogger
+
+	dispatcher *hookDispatcher
+	// mutex guards the subscription slices, which are replaced rather than modified in place so that requests can
+	// iterate over a snapshot without holding it
+	mutex sync.Mutex
+}
+
+// hookDispatcher executes hook callbacks on a bounded pool of background workers.
+type hookDispatcher struct {
+	jobs    chan func()
+	pending sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+// EnableAsync makes the hooks execute on the given number of background workers instead of delaying each request.
+// Up to queueSize events wait for a free worker; once the queue is full, requests block until there is room.
+//
+// In asynchronous mode, hooks may run after the Client method has returned, so the AbortOnHookErrors policy cannot
+// apply and errors are logged instead. EnableAsync must not be called concurrently with requests.
+func (h *Hooks) EnableAsync(workers int, queueSize int) {
+	h.DisableAsync()
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	dispatcher := &hookDispatcher{jobs: make(chan func(), queueSize)}
+	for i := 0; i < workers; i++ {
+		dispatcher.workers.Add(1)
+		go func() {
+			defer dispatcher.workers.Done()
+			for job := range dispatcher.jobs {
+				job()
+				dispatcher.pending.Done()
+			}
+		}()
+	}
+	h.dispatcher = dispatcher
+}
+
+// DisableAsync waits for all pending asynchronous hooks to complete, stops the background workers, and makes hooks
+// execute synchronously again. It must not be called concurrently with requests.
+func (h *Hooks) DisableAsync() {
+	if h.dispatcher == nil {
+		return
+	}
+	close(h.dispatcher.jobs)
+	h.dispatcher.workers.Wait()
+	h.dispatcher = nil
+}
+
+// Flush blocks until all pending asynchronous hooks have completed.
+func (h *Hooks) Flush() {
+	if h.dispatcher != nil {
+		h.dispatcher.pending.Wait()
+	}
+}
+
+// dispatch executes the given hook callback with ctx (asynchronously if enabled) and handles its error according to the
+// ErrorPolicy. Asynchronous hooks may run once the request is over, so they get the values of ctx but not its
+// cancellation.
+func (h *Hooks) dispatch(ctx context.Context, subscriberID string, callback func(ctx context.Context) error) error {
+	if h.dispatcher != nil {
+		detached := detachedContext{parent: ctx}
+		h.dispatcher.pending.Add(1)
+		h.dispatcher.jobs <- func() {
+			if err := callback(detached); err != nil && h.ErrorPolicy != IgnoreHookErrors {
+				h.logError(subscriberID, err)
+			}
+		}
+		return nil
+	}
+
+	err := callback(ctx)
+	if err == nil {
+		return nil
+	}
+	switch h.ErrorPolicy {
+	case LogHookErrors:
+		h.logError(subscriberID, err)
+	case AbortOnHookErrors:
+		return newHookError(subscriberID, err)
+	}
+	return nil
+}
+
+// detachedContext is a context carrying the values of its parent, but neither its deadline nor its cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// logError reports the error of the given subscriber to the ErrorLogger.
+func (h *Hooks) logError(subscriberID string, err error) {
+	if h.ErrorLogger != nil {
+		h.ErrorLogger.Printf("easypost: hook %s failed: %v", subscriberID, err)
+		return
+	}
+	log.Printf("easypost: hook %s failed: %v", subscriberID, err)
+}
+
+// hookBody returns a copy of the given body, for a single subscriber, truncated to the MaxBodySize if needed.
+func (h *Hooks) hookBody(body []byte) (copied []byte, truncated bool) {
+	if body == nil {
+		return nil, false
+	}
+	if h.MaxBodySize > 0 && len(body) > h.MaxBodySize {
+		body = body[:h.MaxBodySize]
+		truncated = true
+	}
+	copied = make([]byte, len(body))
+	copy(copied, body)
+	return copied, truncated
+}
+
+// subscriptions returns a snapshot of the current request and response subscriptions.
+func (h *Hooks) subscriptions() ([]RequestHookEventSubscriber, []ResponseHookEventSubscriber) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.RequestHookEventSubscriptions, h.ResponseHookEventSubscriptions
+}
+
+// fireRequestEvent executes each of the given request hooks with the given event, passing each its own copy of the request body.
+func (h *Hooks) fireRequestEvent(ctx context.Context, subscriptions []RequestHookEventSubscriber, event RequestHookEvent, body []byte) error {
+	for _, hook := range subscriptions {
+		hookEvent := event
+		hookEvent.RequestBodyBytes, hookEvent.RequestBodyTruncated = h.hookBody(body)
+		if hookEvent.RequestBodyBytes != nil {
+			hookEvent.RequestBody = ioutil.NopCloser(bytes.NewReader(hookEvent.RequestBodyBytes))
+		}
+		callback := hook.Callback
+		if err := h.dispatch(ctx, hook.ID, func(ctx context.Context) error { return callback(ctx, hookEvent) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fireResponseEvent executes each of the given response hooks with the given event, passing each its own copy of the response body.
+func (h *Hooks) fireResponseEvent(ctx context.Context, subscriptions []ResponseHookEventSubscriber, event ResponseHookEvent, body []byte) error {
+	for _, hook := range subscriptions {
+		hookEvent := event
+		hookEvent.ResponseBodyBytes, hookEvent.ResponseBodyTruncated = h.hookBody(body)
+		if hookEvent.ResponseBodyBytes != nil {
+			hookEvent.ResponseBody = ioutil.NopCloser(bytes.NewReader(hookEvent.ResponseBodyBytes))
+		}
+		callback := hook.Callback
+		if err := h.dispatch(ctx, hook.ID, func(ctx context.Context) error { return callback(ctx, hookEvent) }); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // AddRequestEventSubscriber adds a RequestHookEventSubscriber to the Hooks instance to be executed when a RequestHookEvent is fired
 func (h *Hooks) AddRequestEventSubscriber(subscriber RequestHookEventSubscriber) {
-	h.RequestHookEventSubscriptions = append(h.RequestHookEventSubscriptions, subscriber)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// keep the subscriptions sorted by priority, after any existing subscriber with the same priority
+	current := h.RequestHookEventSubscriptions
+	index := len(current)
+	for i, sub := range current {
+		if sub.Priority > subscriber.Priority {
+			index = i
+			break
+		}
+	}
+	subscriptions := make([]RequestHookEventSubscriber, 0, len(current)+1)
+	subscriptions = append(subscriptions, current[:index]...)
+	subscriptions = append(subscriptions, subscriber)
+	h.RequestHookEventSubscriptions = append(subscriptions, current[index:]...)
 }
 
 // AddResponseEventSubscriber adds a ResponseHookEventSubscriber to the Hooks instance to be executed when a ResponseHookEvent is fired
 func (h *Hooks) AddResponseEventSubscriber(subscriber ResponseHookEventSubscriber) {
-	h.ResponseHookEventSubscriptions = append(h.ResponseHookEventSubscriptions, subscriber)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// keep the subscriptions sorted by priority, after any existing subscriber with the same priority
+	current := h.ResponseHookEventSubscriptions
+	index := len(current)
+	for i, sub := range current {
+		if sub.Priority > subscriber.Priority {
+			index = i
+			break
+		}
+	}
+	subscriptions := make([]ResponseHookEventSubscriber, 0, len(current)+1)
+	subscriptions = append(subscriptions, current[:index]...)
+	subscriptions = append(subscriptions, subscriber)
+	h.ResponseHookEventSubscriptions = append(subscriptions, current[index:]...)
 }
 
 // RemoveRequestEventSubscriber removes a RequestHookEventSubscriber from the Hooks instance
 func (h *Hooks) RemoveRequestEventSubscriber(subscriber RequestHookEventSubscriber) {
-	for i, sub := range h.RequestHookEventSubscriptions {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	current := h.RequestHookEventSubscriptions
+	for i, sub := range current {
 		if sub.ID == subscriber.ID {
-			h.RequestHookEventSubscriptions = append(h.RequestHookEventSubscriptions[:i], h.RequestHookEventSubscriptions[i+1:]...)
+			subscriptions := make([]RequestHookEventSubscriber, 0, len(current)-1)
+			subscriptions = append(subscriptions, current[:i]...)
+			h.RequestHookEventSubscriptions = append(subscriptions, current[i+1:]...)
 			return
 		}
 	}
@@ -104,9 +370,15 @@ func (h *Hooks) RemoveRequestEventSubscriber(subscriber RequestHookEventSubscrib
 
 // RemoveResponseEventSubscriber removes a ResponseHookEventSubscriber from the Hooks instance
 func (h *Hooks) RemoveResponseEventSubscriber(subscriber ResponseHookEventSubscriber) {
-	for i, sub := range h.ResponseHookEventSubscriptions {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	current := h.ResponseHookEventSubscriptions
+	for i, sub := range current {
 		if sub.ID == subscriber.ID {
-			h.ResponseHookEventSubscriptions = append(h.ResponseHookEventSubscriptions[:i], h.ResponseHookEventSubscriptions[i+1:]...)
+			subscriptions := make([]ResponseHookEventSubscriber, 0, len(current)-1)
+			subscriptions = append(subscriptions, current[:i]...)
+			h.ResponseHookEventSubscriptions = append(subscriptions, current[i+1:]...)
 			return
 		}
 	}
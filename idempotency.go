@@ -0,0 +1,96 @@
+package easypost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader is the HTTP header used to send the idempotency key of a purchase request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKeyContextKey struct{}
+
+// purchaseSentContextKey is the key of the flag set in the context of a purchase once its request has been sent.
+type purchaseSentContextKey struct{}
+
+// WithIdempotencyKey returns a copy of ctx carrying the given idempotency key.
+//
+// Purchase calls (e.g. BuyShipmentWithContext, BuyBatchWithContext, FundWalletWithContext) made with the returned
+// context send this key instead of generating a new one. Reusing the same key when repeating a purchase whose outcome
+// is unknown (e.g. after a timeout) ensures the purchase is not made twice.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key stored in ctx by WithIdempotencyKey, if any.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok && key != ""
+}
+
+// NewIdempotencyKey generates a new random idempotency key.
+func NewIdempotencyKey() string {
+	return uuid.New().String()
+}
+
+// purchase sends a POST request that buys postage or charges a payment method.
+//
+// The purchase is refused if the API key or the given objects do not have the ExpectedMode of the Client, or if the
+// labels it buys violate the SpendingPolicy of the Client. The request carries an idempotency key, taken from ctx if
+// present or generated otherwise, which stays the same across all attempts of the request.
+func (c *Client) purchase(ctx context.Context, path string, in, out interface{}, purchases ...plannedPurchase) error {
+	req, err := c.newRequest(ctx, http.MethodPost, path, in)
+	if err != nil {
+		return err
+	}
+	if err := c.checkMode(req, in); err != nil {
+		return err
+	}
+	reservation, err := c.SpendingPolicy.reserve(ctx, purchases)
+	if err != nil {
+		return err
+	}
+
+	key, ok := IdempotencyKeyFromContext(ctx)
+	if !ok {
+		key = NewIdempotencyKey()
+	}
+	req.Header.Set(IdempotencyKeyHeader, key)
+
+	var sent int32
+	req = req.WithContext(context.WithValue(req.Context(), purchaseSentContextKey{}, &sent))
+	if err := c.doRequest(req, out); err != nil {
+		// a purchase that failed after being sent, e.g. with a timeout, may have been made
+		if atomic.LoadInt32(&sent) == 0 || isRejectedPurchase(err) {
+			c.SpendingPolicy.release(ctx, reservation)
+		}
+		return err
+	}
+	c.SpendingPolicy.settle(ctx, reservation, out)
+	return nil
+}
+
+// markPurchaseSent records that the given request, if it is a purchase, is about to be sent.
+func markPurchaseSent(req *http.Request) {
+	if sent, ok := req.Context().Value(purchaseSentContextKey{}).(*int32); ok && req.Header.Get(IdempotencyKeyHeader) != "" {
+		atomic.StoreInt32(sent, 1)
+	}
+}
+
+// isRejectedPurchase returns true if the given error of a purchase is an unsuccessful response of the API, which did
+// not make the purchase.
+func isRejectedPurchase(err error) bool {
+	var apiErr interface{ apiError() *APIError }
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	statusCode := apiErr.apiError().StatusCode
+	return statusCode != 0 && (statusCode < 200 || statusCode > 299)
+}
@@ -120,3 +120,28 @@ func (c *Client) GetInsuranceWithContext(ctx context.Context, insuranceID string
 	err = c.get(ctx, "insurances/"+insuranceID, &out)
 	return
 }
+
+// IterateInsurances returns a ListIterator over the insurances matching the given options, whose items are of type
+// *Insurance.
+func (c *Client) IterateInsurances(opts *ListOptions) *ListIterator {
+	return c.IterateInsurancesWithContext(context.Background(), opts)
+}
+
+// IterateInsurancesWithContext performs the same operation as IterateInsurances, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateInsurancesWithContext(ctx context.Context, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "insurances", copyListOptions(opts))
+}
+
+// listInsurancesPage fetches a page of insurances for a ListIterator.
+func listInsurancesPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	result, err := c.ListInsurancesWithContext(ctx, pageListOptions(options.(*ListOptions), beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, insurance := range result.Insurances {
+		page.add(insurance, insurance.ID)
+	}
+	return page, nil
+}
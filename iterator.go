@@ -0,0 +1,219 @@
+package easypost
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// ListIterator iterates over the objects of a paginated list endpoint, fetching pages as needed. It is created with
+// the Iterate... methods of a Client, e.g. IterateShipments, and used like a bufio.Scanner:
+//
+//	it := client.IterateShipments(&easypost.ListShipmentsOptions{PageSize: 100})
+//	for it.Next() {
+//		shipment := it.Item().(*easypost.Shipment)
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// The position of an iterator can be saved with Cursor and restored with ResumeListIterator, e.g. to resume a long
+// export after a crash. A ListIterator is not safe for concurrent use.
+type ListIterator struct {
+	// MaxItems is the maximum number of objects returned by the iterator, including those returned before it was
+	// resumed. If zero, all objects are returned.
+	MaxItems int
+
+	client   *Client
+	ctx      context.Context
+	resource string
+	options  interface{}
+	fetch    listPageFetcher
+
+	page     *listPage
+	index    int
+	beforeID string
+	count    int
+	item     interface{}
+	done     bool
+	err      error
+}
+
+// listPage is a page of objects returned by a list endpoint, along with their IDs.
+type listPage struct {
+	items   []interface{}
+	ids     []string
+	hasMore bool
+}
+
+// add appends an object to the page.
+func (p *listPage) add(item interface{}, id string) {
+	p.items = append(p.items, item)
+	p.ids = append(p.ids, id)
+}
+
+// listPageFetcher fetches the page of objects listed with the given options that precede the object with the given
+// ID, or the first page if beforeID is empty.
+type listPageFetcher func(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error)
+
+// listEndpoint describes a list endpoint supporting iteration.
+type listEndpoint struct {
+	// newOptions returns a pointer to new, empty options of the endpoint.
+	newOptions func() interface{}
+	fetch      listPageFetcher
+}
+
+// listEndpoints are the list endpoints supporting iteration, by resource name.
+var listEndpoints = map[string]listEndpoint{
+	"addresses":          {func() interface{} { return &ListOptions{} }, listAddressesPage},
+	"batches":            {func() interface{} { return &ListOptions{} }, listBatchesPage},
+	"end_shippers":       {func() interface{} { return &ListOptions{} }, listEndShippersPage},
+	"events":             {func() interface{} { return &ListOptions{} }, listEventsPage},
+	"insurances":         {func() interface{} { return &ListOptions{} }, listInsurancesPage},
+	"pickups":            {func() interface{} { return &ListOptions{} }, listPickupsPage},
+	"referral_customers": {func() interface{} { return &ListOptions{} }, listReferralCustomersPage},
+	"refunds":            {func() interface{} { return &ListOptions{} }, listRefundsPage},
+	"reports":            {func() interface{} { return &listReportsOptions{} }, listReportsPage},
+	"scan_forms":         {func() interface{} { return &ListOptions{} }, listScanFormsPage},
+	"shipments":          {func() interface{} { return &ListShipmentsOptions{} }, listShipmentsPage},
+	"trackers":           {func() interface{} { return &ListTrackersOptions{} }, listTrackersPage},
+}
+
+// newListIterator returns a ListIterator over the objects of the given resource, listed with the given options.
+func (c *Client) newListIterator(ctx context.Context, resource string, options interface{}) *ListIterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &ListIterator{
+		client:   c,
+		ctx:      ctx,
+		resource: resource,
+		options:  options,
+		fetch:    listEndpoints[resource].fetch,
+	}
+}
+
+// Next advances the iterator to the next object, fetching the next page if needed, which is then available through
+// Item. It returns false when there are no more objects, MaxItems have been returned, or an error occurred.
+func (it *ListIterator) Next() bool {
+	it.item = nil
+	if it.done || it.err != nil {
+		return false
+	}
+	if it.MaxItems > 0 && it.count >= it.MaxItems {
+		it.done = true
+		return false
+	}
+
+	for it.page == nil || it.index >= len(it.page.items) {
+		if it.page != nil && !it.page.hasMore {
+			it.done = true
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		page, err := it.fetch(it.ctx, it.client, it.options, it.beforeID)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.items) == 0 {
+			// an empty page has no last ID to fetch the next one from
+			page.hasMore = false
+		}
+		it.page, it.index = page, 0
+	}
+
+	it.item = it.page.items[it.index]
+	it.beforeID = it.page.ids[it.index]
+	it.index++
+	it.count++
+	return true
+}
+
+// Item returns the object the iterator is at, after a call to Next returned true. Its type depends on the method the
+// iterator was created with, e.g. *Shipment for IterateShipments.
+func (it *ListIterator) Item() interface{} {
+	return it.item
+}
+
+// Err returns the error that stopped the iterator, if any. It is nil once all objects have been returned.
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+// Count returns the number of objects returned by the iterator so far, including those returned before it was resumed.
+func (it *ListIterator) Count() int {
+	return it.count
+}
+
+// listCursor is the position of a ListIterator, serialized by Cursor.
+type listCursor struct {
+	Resource string          `json:"resource"`
+	Options  json.RawMessage `json:"options,omitempty"`
+	BeforeID string          `json:"before_id,omitempty"`
+	Count    int             `json:"count,omitempty"`
+	MaxItems int             `json:"max_items,omitempty"`
+	Done     bool            `json:"done,omitempty"`
+}
+
+// Cursor returns an opaque string recording the position of the iterator, just after the last object returned by
+// Item. An iterator resumed from it with ResumeListIterator returns the objects that follow.
+func (it *ListIterator) Cursor() (string, error) {
+	options, err := json.Marshal(it.options)
+	if err != nil {
+		return "", err
+	}
+	cursor := listCursor{
+		Resource: it.resource,
+		Options:  options,
+		BeforeID: it.beforeID,
+		Count:    it.count,
+		MaxItems: it.MaxItems,
+		Done:     it.done || (it.page != nil && !it.page.hasMore && it.index >= len(it.page.items)),
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// ResumeListIterator returns a ListIterator resuming the iteration recorded by a cursor returned by
+// ListIterator.Cursor.
+func (c *Client) ResumeListIterator(cursor string) (*ListIterator, error) {
+	return c.ResumeListIteratorWithContext(context.Background(), cursor)
+}
+
+// ResumeListIteratorWithContext performs the same operation as ResumeListIterator, but allows specifying a context
+// that can interrupt the requests of the iterator.
+func (c *Client) ResumeListIteratorWithContext(ctx context.Context, cursor string) (*ListIterator, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, newInvalidObjectError(InvalidListCursor)
+	}
+	var position listCursor
+	if err := json.Unmarshal(data, &position); err != nil {
+		return nil, newInvalidObjectError(InvalidListCursor)
+	}
+	endpoint, ok := listEndpoints[position.Resource]
+	if !ok {
+		return nil, newInvalidObjectError(InvalidListCursor)
+	}
+	options := endpoint.newOptions()
+	if len(position.Options) > 0 {
+		if err := json.Unmarshal(position.Options, options); err != nil {
+			return nil, newInvalidObjectError(InvalidListCursor)
+		}
+	}
+
+	it := c.newListIterator(ctx, position.Resource, options)
+	it.beforeID = position.BeforeID
+	it.count = position.Count
+	it.MaxItems = position.MaxItems
+	it.done = position.Done
+	return it, nil
+}
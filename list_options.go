@@ -24,3 +24,22 @@ func nextPageParameters(hasMore bool, lastID string, pageSize int) (out *ListOpt
 	}
 	return
 }
+
+// copyListOptions returns a copy of the given options, or empty options if nil.
+func copyListOptions(opts *ListOptions) *ListOptions {
+	if opts == nil {
+		return &ListOptions{}
+	}
+	out := *opts
+	return &out
+}
+
+// pageListOptions returns a copy of the given options listing the objects preceding the one with the given ID, if
+// not empty.
+func pageListOptions(opts *ListOptions, beforeID string) *ListOptions {
+	out := copyListOptions(opts)
+	if beforeID != "" {
+		out.BeforeID = beforeID
+	}
+	return out
+}
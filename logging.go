@@ -0,0 +1,261 @@
+package easypost
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogLevel is the severity of a log record.
+type LogLevel int
+
+const (
+	// LogLevelDebug records include the redacted request and response bodies.
+	LogLevelDebug LogLevel = iota - 1
+	// LogLevelInfo is used for successful API calls. It is the default minimum level.
+	LogLevelInfo
+	// LogLevelWarn is used for API calls that failed with a 4xx status code.
+	LogLevelWarn
+	// LogLevelError is used for API calls that failed with a 5xx status code or did not get a response.
+	LogLevelError
+)
+
+// String returns the lowercase name of the level, e.g. "info".
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return "level(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
+// LogField is a key/value pair attached to a log record.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// Keys of the fields attached to the log record of an API call.
+const (
+	LogFieldMethod            = "method"
+	LogFieldPath              = "path"
+	LogFieldStatus            = "status"
+	LogFieldDuration          = "duration"
+	LogFieldRequestTimestamp  = "request_timestamp"
+	LogFieldResponseTimestamp = "response_timestamp"
+	LogFieldRequestID         = "request_id"
+	LogFieldAttempts          = "attempts"
+	LogFieldErrorCode         = "error_code"
+	LogFieldError             = "error"
+	LogFieldRequestBody       = "request_body"
+	LogFieldResponseBody      = "response_body"
+)
+
+// logMessage is the message of the log record of an API call.
+const logMessage = "easypost api call"
+
+// Logger receives the structured log records of a Client (via its Logger property).
+//
+// NewStdLogger, NewKeyValueLogger and NewKitLogger adapt common loggers to this interface; LoggerFunc adapts a function.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, message string, fields []LogField)
+}
+
+// LoggerFunc is a function implementing the Logger interface.
+type LoggerFunc func(ctx context.Context, level LogLevel, message string, fields []LogField)
+
+// Log calls f(ctx, level, message, fields).
+func (f LoggerFunc) Log(ctx context.Context, level LogLevel, message string, fields []LogField) {
+	f(ctx, level, message, fields)
+}
+
+// stdLogger writes records to a *log.Logger in logfmt format.
+type stdLogger struct {
+	logger *log.Logger
+}
+
+// NewStdLogger returns a Logger writing records to the given *log.Logger, one line per record in logfmt format
+// (e.g. `level=info msg="easypost api call" method=GET status=200`). If logger is nil, the standard logger is used.
+func NewStdLogger(logger *log.Logger) Logger {
+	return &stdLogger{logger: logger}
+}
+
+// Log formats the record and writes it to the underlying logger.
+func (l *stdLogger) Log(_ context.Context, level LogLevel, message string, fields []LogField) {
+	var line strings.Builder
+	line.WriteString("level=")
+	line.WriteString(level.String())
+	line.WriteString(" msg=")
+	line.WriteString(logfmtValue(message))
+	for _, field := range fields {
+		line.WriteString(" ")
+		line.WriteString(field.Key)
+		line.WriteString("=")
+		line.WriteString(logfmtValue(field.Value))
+	}
+
+	if l.logger != nil {
+		l.logger.Print(line.String())
+		return
+	}
+	log.Print(line.String())
+}
+
+// logfmtValue formats a value for a logfmt line, quoting it if needed.
+func logfmtValue(value interface{}) string {
+	var s string
+	switch value := value.(type) {
+	case []byte:
+		s = string(value)
+	case time.Time:
+		s = value.Format(time.RFC3339Nano)
+	default:
+		s = fmt.Sprint(value)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// KeyValueLogger is implemented by structured loggers taking alternating keys and values, such as zap's SugaredLogger.
+type KeyValueLogger interface {
+	Debugw(message string, keysAndValues ...interface{})
+	Infow(message string, keysAndValues ...interface{})
+	Warnw(message string, keysAndValues ...interface{})
+	Errorw(message string, keysAndValues ...interface{})
+}
+
+// keyValueLogger adapts a KeyValueLogger to the Logger interface.
+type keyValueLogger struct {
+	logger KeyValueLogger
+}
+
+// NewKeyValueLogger returns a Logger writing records to the given KeyValueLogger, such as a *zap.SugaredLogger.
+func NewKeyValueLogger(logger KeyValueLogger) Logger {
+	return &keyValueLogger{logger: logger}
+}
+
+// Log writes the record with the method of the underlying logger matching its level.
+func (l *keyValueLogger) Log(_ context.Context, level LogLevel, message string, fields []LogField) {
+	keysAndValues := flattenLogFields(nil, fields)
+	switch {
+	case level <= LogLevelDebug:
+		l.logger.Debugw(message, keysAndValues...)
+	case level == LogLevelInfo:
+		l.logger.Infow(message, keysAndValues...)
+	case level == LogLevelWarn:
+		l.logger.Warnw(message, keysAndValues...)
+	default:
+		l.logger.Errorw(message, keysAndValues...)
+	}
+}
+
+// KitLogger is implemented by loggers taking a single list of alternating keys and values, such as go-kit's log.Logger.
+type KitLogger interface {
+	Log(keyvals ...interface{}) error
+}
+
+// kitLogger adapts a KitLogger to the Logger interface.
+type kitLogger struct {
+	logger KitLogger
+}
+
+// NewKitLogger returns a Logger writing records to the given KitLogger, such as a go-kit log.Logger. The level and
+// message are passed with the "level" and "msg" keys.
+func NewKitLogger(logger KitLogger) Logger {
+	return &kitLogger{logger: logger}
+}
+
+// Log writes the record to the underlying logger, ignoring any error it returns.
+func (l *kitLogger) Log(_ context.Context, level LogLevel, message string, fields []LogField) {
+	keyvals := flattenLogFields([]interface{}{"level", level.String(), "msg", message}, fields)
+	_ = l.logger.Log(keyvals...)
+}
+
+// flattenLogFields appends the keys and values of the given fields to keyvals.
+func flattenLogFields(keyvals []interface{}, fields []LogField) []interface{} {
+	for _, field := range fields {
+		value := field.Value
+		if body, ok := value.([]byte); ok {
+			value = string(body)
+		}
+		keyvals = append(keyvals, field.Key, value)
+	}
+	return keyvals
+}
+
+// callStatus returns the HTTP status code and EasyPost error code of a completed API call. The status code is 0 if
+// no response was received.
+func callStatus(res *http.Response, err error) (int, string) {
+	if apiErr, ok := err.(apiErrorer); ok {
+		return apiErr.apiError().StatusCode, apiErr.apiError().Code
+	}
+	if res != nil {
+		return res.StatusCode, ""
+	}
+	return 0, ""
+}
+
+// logBodies returns true if the log records of the Client include request and response bodies.
+func (c *Client) logBodies() bool {
+	return c.Logger != nil && c.LogLevel <= LogLevelDebug
+}
+
+// logCall sends the log record of a completed API call to the Logger of the Client, if any.
+func (c *Client) logCall(req *http.Request, call *callInfo, res *http.Response, err error) {
+	if c.Logger == nil {
+		return
+	}
+
+	status, errorCode := callStatus(res, err)
+	level := LogLevelInfo
+	if err != nil {
+		level = LogLevelError
+		if status >= 400 && status <= 499 {
+			level = LogLevelWarn
+		}
+	}
+	if level < c.LogLevel {
+		return
+	}
+
+	requestTimestamp, responseTimestamp := call.timestamps()
+
+	fields := []LogField{
+		{Key: LogFieldMethod, Value: req.Method},
+		{Key: LogFieldPath, Value: req.URL.Path},
+		{Key: LogFieldStatus, Value: status},
+		{Key: LogFieldDuration, Value: responseTimestamp.Sub(requestTimestamp)},
+		{Key: LogFieldRequestTimestamp, Value: requestTimestamp},
+		{Key: LogFieldResponseTimestamp, Value: responseTimestamp},
+		{Key: LogFieldRequestID, Value: call.id.String()},
+		{Key: LogFieldAttempts, Value: call.attempts},
+	}
+	if errorCode != "" {
+		fields = append(fields, LogField{Key: LogFieldErrorCode, Value: errorCode})
+	}
+	if err != nil {
+		fields = append(fields, LogField{Key: LogFieldError, Value: err.Error()})
+	}
+	if c.logBodies() {
+		requestBody, _ := readRequestBody(req)
+		fields = append(fields,
+			LogField{Key: LogFieldRequestBody, Value: c.redactor().RedactBody(requestBody)},
+			LogField{Key: LogFieldResponseBody, Value: c.redactor().RedactBody(call.responseBody)},
+		)
+	}
+
+	c.Logger.Log(req.Context(), level, logMessage, fields)
+}
@@ -0,0 +1,348 @@
+package easypost
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CallMetrics describes a completed API call, as recorded by a MetricsCollector.
+type CallMetrics struct {
+	// Endpoint is the endpoint template of the call, e.g. "shipments/{id}/buy".
+	Endpoint string
+	Method   string
+	// StatusCode is the HTTP status code of the last response, or 0 if none was received.
+	StatusCode int
+	// ErrorType is the name of the type of the error returned by the call (e.g. "NotFoundError" or "RateLimitError"),
+	// or an empty string if the call succeeded.
+	ErrorType string
+	// Duration is the time between sending the first attempt of the call and receiving its last response.
+	Duration time.Duration
+	Attempts int
+}
+
+// LabelPurchase describes a label purchased by an API call, as recorded by a MetricsCollector.
+type LabelPurchase struct {
+	Carrier string
+	Service string
+	// Currency is the currency of the Postage, e.g. "USD".
+	Currency string
+	// Postage is the amount charged for the label, taken from the postage fee of the shipment, or from its selected
+	// rate if there is no such fee.
+	Postage float64
+}
+
+// MetricsCollector records the metrics of the API calls made by a Client (via its Metrics property). Implementations
+// must be safe for concurrent use.
+type MetricsCollector interface {
+	// ObserveCall records a completed API call.
+	ObserveCall(call CallMetrics)
+	// ObserveLabelPurchase records a label bought with BuyShipment or BuyOrder.
+	ObserveLabelPurchase(purchase LabelPurchase)
+}
+
+// recordMetrics sends the metrics of a completed API call, and of the labels it purchased, to the MetricsCollector of
+// the Client, if any.
+func (c *Client) recordMetrics(req *http.Request, call *callInfo, res *http.Response, err error, out interface{}) {
+	if c.Metrics == nil {
+		return
+	}
+
+	status, _ := callStatus(res, err)
+	requestTimestamp, responseTimestamp := call.timestamps()
+	c.Metrics.ObserveCall(CallMetrics{
+		Endpoint:   c.endpointTemplateOf(req),
+		Method:     req.Method,
+		StatusCode: status,
+		ErrorType:  errorTypeOf(err),
+		Duration:   responseTimestamp.Sub(requestTimestamp),
+		Attempts:   call.attempts,
+	})
+
+	// only purchase requests carry an idempotency key; other calls returning bought shipments must not be counted
+	if err != nil || req.Header.Get(IdempotencyKeyHeader) == "" {
+		return
+	}
+	for _, shipment := range purchasedShipments(out) {
+		if purchase, ok := labelPurchaseOf(shipment); ok {
+			c.Metrics.ObserveLabelPurchase(purchase)
+		}
+	}
+}
+
+// errorTypeOf returns the name of the type of the given error, e.g. "NotFoundError", or an empty string if err is nil.
+func errorTypeOf(err error) string {
+	switch err {
+	case nil:
+		return ""
+	case context.Canceled:
+		return "Canceled"
+	case context.DeadlineExceeded:
+		return "DeadlineExceeded"
+	}
+	t := reflect.TypeOf(err)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.PkgPath() != reflect.TypeOf(Client{}).PkgPath() {
+		// the types of other packages are often unexported and meaningless, e.g. *errors.errorString
+		return "Error"
+	}
+	return t.Name()
+}
+
+// purchasedShipments returns the shipments found in the decoded response of a purchase request.
+func purchasedShipments(out interface{}) []*Shipment {
+	switch out := out.(type) {
+	case **Shipment:
+		if *out != nil {
+			return []*Shipment{*out}
+		}
+	case *Shipment:
+		return []*Shipment{out}
+	case **Order:
+		if *out != nil {
+			return (*out).Shipments
+		}
+	case *Order:
+		return out.Shipments
+	}
+	return nil
+}
+
+// labelPurchaseOf returns the LabelPurchase of a bought shipment, or false if the shipment has no selected rate.
+func labelPurchaseOf(shipment *Shipment) (LabelPurchase, bool) {
+	if shipment == nil || shipment.SelectedRate == nil {
+		return LabelPurchase{}, false
+	}
+	rate := shipment.SelectedRate
+	purchase := LabelPurchase{Carrier: rate.Carrier, Service: rate.Service, Currency: rate.Currency}
+
+	postageFound := false
+	for _, fee := range shipment.Fees {
+		if fee == nil || fee.Type != "PostageFee" || fee.Refunded {
+			continue
+		}
+		if amount, err := strconv.ParseFloat(fee.Amount, 64); err == nil {
+			purchase.Postage += amount
+			postageFound = true
+		}
+	}
+	if !postageFound {
+		purchase.Postage, _ = strconv.ParseFloat(rate.Rate, 64)
+	}
+	return purchase, true
+}
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the request latency histogram of a PrometheusCollector.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// PrometheusCollector is a MetricsCollector keeping the metrics in memory and exposing them in the Prometheus text
+// format. It implements http.Handler, so it can be served directly as a scrape endpoint:
+//
+//	collector := easypost.NewPrometheusCollector("easypost")
+//	client.Metrics = collector
+//	http.Handle("/metrics", collector)
+//
+// It exposes the following metrics, prefixed by the namespace:
+//   - requests_total, by endpoint, method and status code
+//   - request_duration_seconds (histogram), by endpoint and method
+//   - errors_total, by endpoint, method and error type
+//   - labels_purchased_total, by carrier and service
+//   - postage_spent_total, by currency
+type PrometheusCollector struct {
+	namespace string
+	buckets   []float64
+
+	mutex     sync.Mutex
+	requests  map[string]float64
+	durations map[string]*histogram
+	errors    map[string]float64
+	labels    map[string]float64
+	postage   map[string]float64
+}
+
+// histogram holds the cumulative bucket counts, sum and count of a histogram.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheusCollector returns a new PrometheusCollector whose metric names are prefixed by the given namespace
+// (e.g. "easypost" for "easypost_requests_total"). The latency histogram uses the DefaultLatencyBuckets.
+func NewPrometheusCollector(namespace string) *PrometheusCollector {
+	return &PrometheusCollector{
+		namespace: namespace,
+		buckets:   DefaultLatencyBuckets,
+		requests:  make(map[string]float64),
+		durations: make(map[string]*histogram),
+		errors:    make(map[string]float64),
+		labels:    make(map[string]float64),
+		postage:   make(map[string]float64),
+	}
+}
+
+// ObserveCall records a completed API call.
+func (p *PrometheusCollector) ObserveCall(call CallMetrics) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.requests[formatLabels("endpoint", call.Endpoint, "method", call.Method, "status", strconv.Itoa(call.StatusCode))]++
+
+	key := formatLabels("endpoint", call.Endpoint, "method", call.Method)
+	h, ok := p.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		p.durations[key] = h
+	}
+	seconds := call.Duration.Seconds()
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+
+	if call.ErrorType != "" {
+		p.errors[formatLabels("endpoint", call.Endpoint, "method", call.Method, "error_type", call.ErrorType)]++
+	}
+}
+
+// ObserveLabelPurchase records a purchased label and the postage spent on it.
+func (p *PrometheusCollector) ObserveLabelPurchase(purchase LabelPurchase) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.labels[formatLabels("carrier", purchase.Carrier, "service", purchase.Service)]++
+	p.postage[formatLabels("currency", purchase.Currency)] += purchase.Postage
+}
+
+// WriteTo writes all the metrics to w in the Prometheus text exposition format.
+func (p *PrometheusCollector) WriteTo(w io.Writer) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	out := &countingWriter{writer: bufio.NewWriter(w)}
+	p.writeCounter(out, "requests_total", "Number of API calls made.", p.requests)
+	p.writeHistogram(out, "request_duration_seconds", "Duration of API calls, including retries.")
+	p.writeCounter(out, "errors_total", "Number of failed API calls.", p.errors)
+	p.writeCounter(out, "labels_purchased_total", "Number of labels purchased.", p.labels)
+	p.writeCounter(out, "postage_spent_total", "Amount of postage spent on purchased labels.", p.postage)
+	if out.err != nil {
+		return out.count, out.err
+	}
+	return out.count, out.writer.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (p *PrometheusCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+// writeCounter writes a counter metric with all its label sets. The mutex must be held.
+func (p *PrometheusCollector) writeCounter(out *countingWriter, name, help string, values map[string]float64) {
+	name = p.metricName(name)
+	out.printf("# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, labels := range sortedKeys(values) {
+		out.printf("%s{%s} %s\n", name, labels, formatFloat(values[labels]))
+	}
+}
+
+// writeHistogram writes the request duration histogram with all its label sets. The mutex must be held.
+func (p *PrometheusCollector) writeHistogram(out *countingWriter, name, help string) {
+	name = p.metricName(name)
+	out.printf("# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	keys := make([]string, 0, len(p.durations))
+	for key := range p.durations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, labels := range keys {
+		h := p.durations[labels]
+		for i, bound := range p.buckets {
+			out.printf("%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(bound), h.counts[i])
+		}
+		out.printf("%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		out.printf("%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+		out.printf("%s_count{%s} %d\n", name, labels, h.count)
+	}
+}
+
+// metricName returns the full name of a metric, prefixed by the namespace of the collector.
+func (p *PrometheusCollector) metricName(name string) string {
+	if p.namespace == "" {
+		return name
+	}
+	return p.namespace + "_" + name
+}
+
+// countingWriter writes formatted output, counting the bytes written and keeping the first error.
+type countingWriter struct {
+	writer *bufio.Writer
+	count  int64
+	err    error
+}
+
+// printf writes formatted output, unless a previous write failed.
+func (w *countingWriter) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(w.writer, format, args...)
+	w.count += int64(n)
+	w.err = err
+}
+
+// formatLabels formats alternating label names and values as a Prometheus label set, e.g. `carrier="USPS"`.
+func formatLabels(namesAndValues ...string) string {
+	pairs := make([]string, 0, len(namesAndValues)/2)
+	for i := 0; i+1 < len(namesAndValues); i += 2 {
+		pairs = append(pairs, namesAndValues[i]+"=\""+escapeLabelValue(namesAndValues[i+1])+"\"")
+	}
+	return strings.Join(pairs, ",")
+}
+
+// labelValueEscaper escapes the characters that are not allowed in Prometheus label values.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// escapeLabelValue escapes a Prometheus label value.
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// formatFloat formats a sample value as expected by Prometheus.
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// sortedKeys returns the keys of the given map in ascending order.
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,51 @@
+package easypost
+
+import (
+	"net/http"
+)
+
+// Handler sends a request to the EasyPost API and returns its response.
+//
+// A non-2xx response is not an error for a Handler: it is converted into an APIError after the middleware chain
+// returns. Errors returned by a Handler are passed through to the caller of the Client method unchanged.
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to add behavior around the requests made by a Client, e.g. injecting headers, serving
+// responses from a cache, or refusing requests by returning an error without calling next.
+//
+// The Handler passed as next performs the request itself, including rate limiting, retries and hooks. The context of
+// the Client method call is available via req.Context().
+type Middleware func(next Handler) Handler
+
+// Use appends the given middlewares to the Client's middleware chain. Middlewares are executed in the order they were
+// added: the first one receives the request first and the response last.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.Middlewares = append(c.Middlewares, middlewares...)
+}
+
+// handler returns the Handler executing a request through the Client's middleware chain, and then its Cache.
+func (c *Client) handler() Handler {
+	handler := Handler(c.roundTrip)
+	if c.Cache != nil {
+		handler = c.Cache.wrap(c, handler)
+	}
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		handler = c.Middlewares[i](handler)
+	}
+	return handler
+}
+
+// HeaderMiddleware returns a Middleware that sets the given headers on every request.
+func HeaderMiddleware(header http.Header) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			for key, values := range header {
+				req.Header.Del(key)
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+			return next(req)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package easypost
 
 import (
+	"errors"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -8,6 +9,9 @@ import (
 	"strings"
 )
 
+// errNoMatchingMockRequest is returned when a client with mock requests makes a request that none of them match.
+var errNoMatchingMockRequest = errors.New("no matching mock request found")
+
 type MockRequestMatchRule struct {
 	Method          string
 	UrlRegexPattern string
@@ -38,8 +42,36 @@ func (r *MockRequestResponseInfo) AsResponse() *http.Response {
 	}
 }
 
-func (c *Client) findMatchingMockRequest(req *http.Request) *http.Response {
-	for _, mockReq := range c.MockRequests {
+// AddMockRequest adds a MockRequest to the Client. Once it has mock requests, the Client only makes mock requests.
+func (c *Client) AddMockRequest(mockRequest MockRequest) {
+	c.mockMutex.Lock()
+	defer c.mockMutex.Unlock()
+
+	mockRequests := make([]MockRequest, 0, len(c.MockRequests)+1)
+	mockRequests = append(mockRequests, c.MockRequests...)
+	c.MockRequests = append(mockRequests, mockRequest)
+}
+
+// SetMockRequests replaces the mock requests of the Client with a copy of the given ones. If there are none, the
+// Client makes real requests again.
+func (c *Client) SetMockRequests(mockRequests []MockRequest) {
+	c.mockMutex.Lock()
+	defer c.mockMutex.Unlock()
+
+	c.MockRequests = append([]MockRequest(nil), mockRequests...)
+}
+
+// mockRequests returns a snapshot of the current mock requests.
+func (c *Client) mockRequests() []MockRequest {
+	c.mockMutex.Lock()
+	defer c.mockMutex.Unlock()
+
+	return c.MockRequests
+}
+
+// findMatchingMockRequest returns the response of the first of the given mock requests matching the request, or nil.
+func findMatchingMockRequest(mockRequests []MockRequest, req *http.Request) *http.Response {
+	for _, mockReq := range mockRequests {
 		url := req.URL.String()
 		methodMatch := mockReq.MatchRule.Method == "" || mockReq.MatchRule.Method == req.Method
 		urlMatch, _ := regexp.MatchString(mockReq.MatchRule.UrlRegexPattern, url)
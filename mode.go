@@ -0,0 +1,189 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// Modes of API keys and objects, as found in their Mode field.
+const (
+	ModeTest       = "test"
+	ModeProduction = "production"
+)
+
+// modeTracker remembers the mode of the API keys used by a Client.
+type modeTracker struct {
+	mutex sync.Mutex
+	modes map[string]string
+}
+
+// get returns the mode of the given API key, or an empty string if it is unknown.
+func (t *modeTracker) get(apiKey string) string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.modes[apiKey]
+}
+
+// set records the mode of the given API key.
+func (t *modeTracker) set(apiKey, mode string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.modes == nil {
+		t.modes = make(map[string]string)
+	}
+	t.modes[apiKey] = mode
+}
+
+// VerifyMode checks that the API key of the client has the ExpectedMode, e.g. at startup. It returns a
+// ModeMismatchError if it does not, or an error if the mode of the key cannot be determined. The mode is remembered,
+// so purchases made with a mismatched key are refused even if VerifyMode's error is ignored.
+func (c *Client) VerifyMode() error {
+	return c.VerifyModeWithContext(context.Background())
+}
+
+// VerifyModeWithContext performs the same operation as VerifyMode, but allows specifying a context that can interrupt
+// the request.
+func (c *Client) VerifyModeWithContext(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+	return c.verifyMode(ctx, apiKey)
+}
+
+// verifyMode looks up the mode of the given API key, remembers it, and checks it against the ExpectedMode.
+func (c *Client) verifyMode(ctx context.Context, apiKey string) error {
+	keys, err := c.GetAPIKeysWithContext(WithCallOptions(ctx, CallAPIKey(apiKey)))
+	if err != nil {
+		return err
+	}
+
+	mode := findAPIKeyMode(keys, apiKey)
+	if mode == "" {
+		return &LocalError{LibraryError{Message: UnknownAPIKeyMode}}
+	}
+	c.modes.set(apiKey, mode)
+	if c.ExpectedMode != "" && mode != c.ExpectedMode {
+		return newModeMismatchError(c.ExpectedMode, mode)
+	}
+	return nil
+}
+
+// findAPIKeyMode returns the mode of the given API key among the keys of a user and its children, or an empty string
+// if it is not found.
+func findAPIKeyMode(keys *APIKeys, apiKey string) string {
+	if keys == nil {
+		return ""
+	}
+	for _, key := range keys.Keys {
+		if key != nil && key.Key == apiKey {
+			return key.Mode
+		}
+	}
+	for _, child := range keys.Children {
+		if mode := findAPIKeyMode(child, apiKey); mode != "" {
+			return mode
+		}
+	}
+	return ""
+}
+
+// recordMode remembers the mode of the API key of a request from the Mode field of its decoded response, if any.
+func (c *Client) recordMode(req *http.Request, out interface{}) {
+	if c.ExpectedMode == "" {
+		return
+	}
+	apiKey, _, ok := req.BasicAuth()
+	if !ok {
+		return
+	}
+	if mode := modeOf(reflect.ValueOf(out)); mode != "" {
+		c.modes.set(apiKey, mode)
+	}
+}
+
+// checkMode returns a ModeMismatchError if the ExpectedMode of the Client does not match the mode of the API key of a
+// purchase request, or the mode of the objects it is given (e.g. the Rate of a shipment). The mode of a key that is not
+// known yet is verified first, and the purchase is refused if it cannot be determined.
+func (c *Client) checkMode(req *http.Request, in interface{}) error {
+	if c.ExpectedMode == "" {
+		return nil
+	}
+	if apiKey, _, ok := req.BasicAuth(); ok {
+		if err := c.checkKeyMode(req.Context(), apiKey); err != nil {
+			return err
+		}
+	}
+
+	value := indirect(reflect.ValueOf(in))
+	if mode := modeOf(value); mode != "" && mode != c.ExpectedMode {
+		return newModeMismatchError(c.ExpectedMode, mode)
+	}
+	// objects are often wrapped in a request struct, e.g. the Rate of a shipment purchase
+	if value.Kind() == reflect.Struct {
+		for i := 0; i < value.NumField(); i++ {
+			if mode := modeOf(value.Field(i)); mode != "" && mode != c.ExpectedMode {
+				return newModeMismatchError(c.ExpectedMode, mode)
+			}
+		}
+	}
+	return nil
+}
+
+// checkFallbackMode returns a ModeMismatchError if the given request is a purchase about to be sent again with the
+// fallback API key, and this key does not have the ExpectedMode of the Client.
+func (c *Client) checkFallbackMode(req *http.Request) error {
+	if c.ExpectedMode == "" || req.Header.Get(IdempotencyKeyHeader) == "" {
+		return nil
+	}
+	apiKey, _, ok := req.BasicAuth()
+	if !ok {
+		return nil
+	}
+	return c.checkKeyMode(req.Context(), apiKey)
+}
+
+// checkKeyMode returns a ModeMismatchError if the given API key does not have the ExpectedMode of the Client. The mode
+// of a key that is not known yet is verified first.
+func (c *Client) checkKeyMode(ctx context.Context, apiKey string) error {
+	mode := c.modes.get(apiKey)
+	if mode == "" {
+		// the mode is remembered, or an error returned
+		return c.verifyMode(ctx, apiKey)
+	}
+	if mode != c.ExpectedMode {
+		return newModeMismatchError(c.ExpectedMode, mode)
+	}
+	return nil
+}
+
+// modeOf returns the value of the Mode field of the struct behind the given value, or an empty string if there is none.
+func modeOf(value reflect.Value) string {
+	value = indirect(value)
+	if value.Kind() != reflect.Struct {
+		return ""
+	}
+	field := value.FieldByName("Mode")
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
+
+// indirect follows pointers and interfaces until a non-pointer value, or an invalid value if one of them is nil.
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
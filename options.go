@@ -0,0 +1,162 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// An Option configures a Client created with New.
+type Option func(c *Client)
+
+// WithBaseURL sets the location of the API (see Client.BaseURL).
+func WithBaseURL(baseURL *url.URL) Option {
+	return func(c *Client) {
+		c.BaseURL = baseURL
+	}
+}
+
+// WithTimeout sets the time limit for requests made by the Client (see Client.Timeout).
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.Timeout = int(timeout / time.Millisecond)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to make API requests (see Client.Client).
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.Client = client
+	}
+}
+
+// WithUserAgentSuffix appends the given suffix, e.g. the name and version of the application, to the default
+// User-Agent sent with API requests.
+func WithUserAgentSuffix(suffix string) Option {
+	return func(c *Client) {
+		c.UserAgent = defaultUserAgent + " " + suffix
+	}
+}
+
+// WithRequestHook subscribes the given subscriber to the request hook events of the Client.
+func WithRequestHook(subscriber RequestHookEventSubscriber) Option {
+	return func(c *Client) {
+		c.Hooks.AddRequestEventSubscriber(subscriber)
+	}
+}
+
+// WithResponseHook subscribes the given subscriber to the response hook events of the Client.
+func WithResponseHook(subscriber ResponseHookEventSubscriber) Option {
+	return func(c *Client) {
+		c.Hooks.AddResponseEventSubscriber(subscriber)
+	}
+}
+
+// WithRetryPolicy sets how failed requests are retried (see Client.RetryPolicy).
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Client) {
+		c.RetryPolicy = policy
+	}
+}
+
+// WithCache caches the responses of read-only calls (see Client.Cache).
+func WithCache(cache *ResponseCache) Option {
+	return func(c *Client) {
+		c.Cache = cache
+	}
+}
+
+// WithLogger sets the Logger receiving the records of API calls, and their minimum level (see Client.Logger).
+func WithLogger(logger Logger, level LogLevel) Option {
+	return func(c *Client) {
+		c.Logger = logger
+		c.LogLevel = level
+	}
+}
+
+// WithExpectedMode sets the mode (ModeTest or ModeProduction) the API key is expected to have (see Client.ExpectedMode).
+func WithExpectedMode(mode string) Option {
+	return func(c *Client) {
+		c.ExpectedMode = mode
+	}
+}
+
+// WithSpendingPolicy sets the limits of the labels bought by the Client (see Client.SpendingPolicy).
+func WithSpendingPolicy(policy *SpendingPolicy) Option {
+	return func(c *Client) {
+		c.SpendingPolicy = policy
+	}
+}
+
+// callOptions holds the per-call options set by WithCallOptions.
+type callOptions struct {
+	header         http.Header
+	timeout        time.Duration
+	idempotencyKey string
+	apiKey         string
+}
+
+type callOptionsContextKey struct{}
+
+// A CallOption configures the API calls made with a context returned by WithCallOptions.
+type CallOption func(o *callOptions)
+
+// CallHeader adds a header to the requests of the call.
+func CallHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// CallTimeout sets the time limit of the call, including all its attempts and reading the response. It applies in
+// addition to the Timeout of the Client, which limits each attempt.
+func CallTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// CallIdempotencyKey sets the idempotency key of a purchase call (see WithIdempotencyKey).
+func CallIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// CallAPIKey makes the call with the given API key instead of the APIKey of the Client, e.g. to act on behalf of a
+// referral customer.
+func CallAPIKey(apiKey string) CallOption {
+	return func(o *callOptions) {
+		o.apiKey = apiKey
+	}
+}
+
+// WithCallOptions returns a copy of ctx carrying the given per-call options, which apply to the API calls made with
+// the ...WithContext methods of a Client. Options are added to any options already carried by ctx.
+//
+//	ctx = easypost.WithCallOptions(ctx, easypost.CallHeader("X-Request-Source", "warehouse-1"), easypost.CallTimeout(5*time.Second))
+//	shipment, err := client.GetShipmentWithContext(ctx, shipmentID)
+func WithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	options := callOptions{}
+	if existing, ok := callOptionsFromContext(ctx); ok {
+		options = *existing
+		options.header = existing.header.Clone()
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.idempotencyKey != "" {
+		ctx = WithIdempotencyKey(ctx, options.idempotencyKey)
+	}
+	return context.WithValue(ctx, callOptionsContextKey{}, &options)
+}
+
+// callOptionsFromContext returns the per-call options carried by ctx, if any.
+func callOptionsFromContext(ctx context.Context) (*callOptions, bool) {
+	options, ok := ctx.Value(callOptionsContextKey{}).(*callOptions)
+	return options, ok
+}
@@ -113,10 +113,41 @@ func (c *Client) BuyOrderWithContext(ctx context.Context, orderID, carrier, serv
 		"carrier": []string{carrier},
 		"service": []string{service},
 	}
-	err = c.post(ctx, "orders/"+orderID+"/buy", vals, &out)
+	purchases := []plannedPurchase{{carrier: carrier, service: service, unpriced: true}}
+	if c.SpendingPolicy.needsRates() {
+		// the rates of the order are needed to enforce the spending policy, there is no need to fetch them for a
+		// service that is not allowed
+		if err = c.SpendingPolicy.checkAllowed(carrier, service); err != nil {
+			return
+		}
+		var order *Order
+		if order, err = c.GetOrderWithContext(ctx, orderID); err != nil {
+			return
+		}
+		purchases = plannedOrderPurchases(order, carrier, service)
+	}
+	err = c.purchase(ctx, "orders/"+orderID+"/buy", vals, &out, purchases...)
 	return
 }
 
+// plannedOrderPurchases returns the plannedPurchase of each label of an order bought with the given carrier and
+// service, priced with the rates of its shipments.
+func plannedOrderPurchases(order *Order, carrier, service string) []plannedPurchase {
+	if order == nil {
+		return []plannedPurchase{{carrier: carrier, service: service, unpriced: true}}
+	}
+	var purchases []plannedPurchase
+	for _, shipment := range order.Shipments {
+		if shipment != nil {
+			purchases = append(purchases, plannedService(shipment.Rates, carrier, service, ""))
+		}
+	}
+	if len(purchases) == 0 {
+		purchases = append(purchases, plannedService(order.Rates, carrier, service, ""))
+	}
+	return purchases
+}
+
 // LowestOrderRate gets the lowest rate of an order
 func (c *Client) LowestOrderRate(order *Order) (out Rate, err error) {
 	return c.LowestOrderRateWithCarrier(order, nil)
@@ -106,7 +106,7 @@ func (c *Client) BuyPickupWithContext(ctx context.Context, pickupID string, rate
 	vals := url.Values{
 		"carrier": []string{rate.Carrier}, "service": []string{rate.Service},
 	}
-	err = c.post(ctx, "pickups/"+pickupID+"/buy", vals, &out)
+	err = c.purchase(ctx, "pickups/"+pickupID+"/buy", vals, &out, plannedPurchase{carrier: rate.Carrier, service: rate.Service, rate: rate.Rate})
 	return
 }
 
@@ -181,3 +181,28 @@ func (c *Client) GetNextPickupPageWithPageSizeWithContext(ctx context.Context, c
 	}
 	return c.ListPickupsWithContext(ctx, params)
 }
+
+// IteratePickups returns a ListIterator over the pickups matching the given options, whose items are of type
+// *Pickup.
+func (c *Client) IteratePickups(opts *ListOptions) *ListIterator {
+	return c.IteratePickupsWithContext(context.Background(), opts)
+}
+
+// IteratePickupsWithContext performs the same operation as IteratePickups, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IteratePickupsWithContext(ctx context.Context, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "pickups", copyListOptions(opts))
+}
+
+// listPickupsPage fetches a page of pickups for a ListIterator.
+func listPickupsPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	result, err := c.ListPickupsWithContext(ctx, pageListOptions(options.(*ListOptions), beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, pickup := range result.Pickups {
+		page.add(pickup, pickup.ID)
+	}
+	return page, nil
+}
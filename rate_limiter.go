@@ -0,0 +1,160 @@
+package easypost
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultEndpoint is the bucket key used for requests to endpoints without a specific limit.
+const defaultEndpoint = ""
+
+// RateLimit describes a token bucket: a sustained number of requests per second, and how many requests can be made
+// in a burst above that rate.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate of requests allowed. Values of 0 or less disable the limit.
+	RequestsPerSecond float64
+	// Burst is the maximum number of requests that can be made at once. Values less than 1 are treated as 1.
+	Burst int
+}
+
+// RateLimiter throttles the requests made by one or more Client instances (via their RateLimiter property) to stay
+// under the API's rate limits. It is safe for concurrent use.
+//
+// When the API responds with a 429 status code (RateLimitError), the rate of the affected endpoint is temporarily
+// reduced and then gradually restored as requests succeed again.
+type RateLimiter struct {
+	mutex   sync.Mutex
+	limits  map[string]RateLimit
+	buckets map[string]*tokenBucket
+}
+
+// tokenBucket tracks the available tokens of a single RateLimit, along with its current (possibly reduced) rate.
+type tokenBucket struct {
+	limit  RateLimit
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// minRateFraction is the lowest fraction of the configured rate that a bucket is slowed down to.
+const minRateFraction = 0.1
+
+// rateRecoveryFraction is the fraction of the configured rate that is restored after each successful request.
+const rateRecoveryFraction = 0.05
+
+// NewRateLimiter returns a new RateLimiter allowing the given number of requests per second (with the given burst)
+// across all endpoints.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		limits: map[string]RateLimit{
+			defaultEndpoint: {RequestsPerSecond: requestsPerSecond, Burst: burst},
+		},
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// SetEndpointLimit sets a separate limit for requests to the given endpoint, the first segment of a request path
+// (e.g. "shipments" or "trackers"). Requests to this endpoint no longer count against the global limit.
+func (l *RateLimiter) SetEndpointLimit(endpoint string, requestsPerSecond float64, burst int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.limits[endpoint] = RateLimit{RequestsPerSecond: requestsPerSecond, Burst: burst}
+	delete(l.buckets, endpoint)
+}
+
+// CurrentRate returns the number of requests per second currently allowed for the given endpoint, which is lower than
+// the configured rate while the limiter is slowed down after a RateLimitError.
+func (l *RateLimiter) CurrentRate(endpoint string) float64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.bucket(endpoint, time.Now()).rate
+}
+
+// Wait blocks until a request to the given endpoint is allowed, or until ctx is done, in which case ctx's error is returned.
+func (l *RateLimiter) Wait(ctx context.Context, endpoint string) error {
+	l.mutex.Lock()
+	now := time.Now()
+	bucket := l.bucket(endpoint, now)
+	if bucket.rate <= 0 {
+		l.mutex.Unlock()
+		return nil
+	}
+	bucket.refill(now)
+	// reserve a token right away, even if it is not available yet, so concurrent callers queue up fairly
+	bucket.tokens--
+	delay := time.Duration(0)
+	if bucket.tokens < 0 {
+		delay = time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+	}
+	l.mutex.Unlock()
+
+	if err := sleepWithContext(ctx, delay); err != nil {
+		// give the reserved token back, the request will not be made
+		l.mutex.Lock()
+		bucket.tokens = math.Min(bucket.tokens+1, float64(bucket.burst()))
+		l.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// observe adjusts the rate of the given endpoint based on the status code of a response received from it.
+func (l *RateLimiter) observe(endpoint string, statusCode int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	bucket := l.bucket(endpoint, time.Now())
+	if bucket.limit.RequestsPerSecond <= 0 {
+		return
+	}
+	if statusCode == http.StatusTooManyRequests {
+		bucket.rate = math.Max(bucket.rate/2, bucket.limit.RequestsPerSecond*minRateFraction)
+	} else if statusCode >= 200 && statusCode <= 299 {
+		bucket.rate = math.Min(bucket.rate+bucket.limit.RequestsPerSecond*rateRecoveryFraction, bucket.limit.RequestsPerSecond)
+	}
+}
+
+// bucket returns the token bucket for the given endpoint, creating it if needed. The mutex must be held.
+func (l *RateLimiter) bucket(endpoint string, now time.Time) *tokenBucket {
+	if _, ok := l.limits[endpoint]; !ok {
+		endpoint = defaultEndpoint
+	}
+	bucket, ok := l.buckets[endpoint]
+	if !ok {
+		limit := l.limits[endpoint]
+		bucket = &tokenBucket{limit: limit, rate: limit.RequestsPerSecond, last: now}
+		bucket.tokens = float64(bucket.burst())
+		l.buckets[endpoint] = bucket
+	}
+	return bucket
+}
+
+// refill adds the tokens accumulated since the last refill, up to the burst size.
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed*b.rate, float64(b.burst()))
+		b.last = now
+	}
+}
+
+// burst returns the maximum number of tokens the bucket can hold.
+func (b *tokenBucket) burst() int {
+	if b.limit.Burst < 1 {
+		return 1
+	}
+	return b.limit.Burst
+}
+
+// endpointOf returns the endpoint of a request, the first segment of its path relative to the base URL (e.g. "shipments").
+func (c *Client) endpointOf(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, c.baseURL().Path)
+	path = strings.TrimPrefix(path, "/")
+	return strings.SplitN(path, "/", 2)[0]
+}
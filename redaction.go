@@ -0,0 +1,169 @@
+package easypost
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultRedactionReplacement is the value that replaces redacted secrets when a Redactor has no Replacement set.
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// Redactor removes secrets, such as the API key or carrier credentials, from the headers and bodies passed to hooks
+// and embedded in errors.
+type Redactor struct {
+	// Headers lists the (case-insensitive) names of the headers whose values are redacted.
+	Headers []string
+	// Fields lists the (case-insensitive) names of the JSON object keys and form fields whose values are redacted,
+	// at any depth. For form fields, the last bracketed segment is compared (e.g. "number" matches "card[number]").
+	Fields []string
+	// Replacement replaces redacted values. If empty, DefaultRedactionReplacement is used.
+	Replacement string
+}
+
+// DefaultRedactor returns a Redactor that redacts authentication headers, API keys, passwords, carrier credentials
+// and payment card details.
+func DefaultRedactor() *Redactor {
+	return &Redactor{
+		Headers: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+		Fields: []string{
+			"api_key", "key", "secret", "webhook_secret", "password", "current_password", "password_confirmation",
+			"credentials", "test_credentials", "number", "cvc", "account_number", "routing_number",
+		},
+	}
+}
+
+// defaultRedactor is used by clients that have no Redactor set.
+var defaultRedactor = DefaultRedactor()
+
+// redactor returns the Redactor of the Client, or the default one if none is set.
+func (c *Client) redactor() *Redactor {
+	if c.Redactor != nil {
+		return c.Redactor
+	}
+	return defaultRedactor
+}
+
+// replacement returns the value replacing redacted secrets.
+func (r *Redactor) replacement() string {
+	if r.Replacement != "" {
+		return r.Replacement
+	}
+	return DefaultRedactionReplacement
+}
+
+// isRedactedField returns true if values of the given JSON key or form field must be redacted.
+func (r *Redactor) isRedactedField(name string) bool {
+	for _, field := range r.Fields {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaders returns a copy of the given headers with the values of redacted headers replaced.
+func (r *Redactor) RedactHeaders(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+	redacted := header.Clone()
+	for _, name := range r.Headers {
+		name = http.CanonicalHeaderKey(name)
+		if values, ok := redacted[name]; ok {
+			replaced := make([]string, len(values))
+			for i := range replaced {
+				replaced[i] = r.replacement()
+			}
+			redacted[name] = replaced
+		}
+	}
+	return redacted
+}
+
+// RedactBody returns the given JSON or form-encoded body with the values of redacted fields replaced.
+// Bodies in other formats, and bodies without any redacted field, are returned unchanged.
+func (r *Redactor) RedactBody(body []byte) []byte {
+	if len(body) == 0 || len(r.Fields) == 0 {
+		return body
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return r.redactJSONBody(body)
+	}
+	return r.redactFormBody(body)
+}
+
+// redactJSONBody redacts the fields of a JSON body.
+func (r *Redactor) redactJSONBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// keep numbers as-is instead of converting them to floats
+	decoder.UseNumber()
+
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return body
+	}
+	if !r.redactJSONValue(data) {
+		return body
+	}
+
+	redacted, err := json.Marshal(data)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactJSONValue recursively redacts the fields of a decoded JSON value, returning true if anything was redacted.
+func (r *Redactor) redactJSONValue(data interface{}) bool {
+	redacted := false
+	switch data := data.(type) {
+	case map[string]interface{}:
+		for key, value := range data {
+			if r.isRedactedField(key) {
+				data[key] = r.replacement()
+				redacted = true
+			} else if r.redactJSONValue(value) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, value := range data {
+			if r.redactJSONValue(value) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// redactFormBody redacts the fields of a form-encoded body.
+func (r *Redactor) redactFormBody(body []byte) []byte {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return body
+	}
+
+	redacted := false
+	for key, fieldValues := range values {
+		name := key
+		// only compare the innermost name of nested fields, e.g. "number" for "card[number]"
+		if start := strings.LastIndex(key, "["); start >= 0 && strings.HasSuffix(key, "]") {
+			name = key[start+1 : len(key)-1]
+		}
+		if r.isRedactedField(name) {
+			for i := range fieldValues {
+				fieldValues[i] = r.replacement()
+			}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return body
+	}
+	return []byte(values.Encode())
+}
@@ -268,3 +268,28 @@ func (c *Client) BetaRefundByPaymentLogWithContext(ctx context.Context, paymentL
 	err = c.post(ctx, "/beta/referral_customers/refunds", params, out)
 	return
 }
+
+// IterateReferralCustomers returns a ListIterator over the referral customers matching the given options, whose items are of type
+// *ReferralCustomer.
+func (c *Client) IterateReferralCustomers(opts *ListOptions) *ListIterator {
+	return c.IterateReferralCustomersWithContext(context.Background(), opts)
+}
+
+// IterateReferralCustomersWithContext performs the same operation as IterateReferralCustomers, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateReferralCustomersWithContext(ctx context.Context, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "referral_customers", copyListOptions(opts))
+}
+
+// listReferralCustomersPage fetches a page of referral customers for a ListIterator.
+func listReferralCustomersPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	result, err := c.ListReferralCustomersWithContext(ctx, pageListOptions(options.(*ListOptions), beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, customer := range result.ReferralCustomers {
+		page.add(customer, customer.ID)
+	}
+	return page, nil
+}
@@ -89,3 +89,28 @@ func (c *Client) GetRefundWithContext(ctx context.Context, refundID string) (out
 	err = c.get(ctx, "refunds/"+refundID, &out)
 	return
 }
+
+// IterateRefunds returns a ListIterator over the refunds matching the given options, whose items are of type
+// *Refund.
+func (c *Client) IterateRefunds(opts *ListOptions) *ListIterator {
+	return c.IterateRefundsWithContext(context.Background(), opts)
+}
+
+// IterateRefundsWithContext performs the same operation as IterateRefunds, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateRefundsWithContext(ctx context.Context, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "refunds", copyListOptions(opts))
+}
+
+// listRefundsPage fetches a page of refunds for a ListIterator.
+func listRefundsPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	result, err := c.ListRefundsWithContext(ctx, pageListOptions(options.(*ListOptions), beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, refund := range result.Refunds {
+		page.add(refund, refund.ID)
+	}
+	return page, nil
+}
@@ -14,8 +14,8 @@ type Report struct {
 	CreatedAt         *DateTime `json:"created_at,omitempty"`
 	UpdatedAt         *DateTime `json:"updated_at,omitempty"`
 	Status            string    `json:"status,omitempty"`
-	StartDate         string    `json:"start_date,omitempty"`
-	EndDate           string    `json:"end_date,omitempty"`
+	StartDate         *Date     `json:"start_date,omitempty"`
+	EndDate           *Date     `json:"end_date,omitempty"`
 	IncludeChildren   bool      `json:"include_children,omitempty"`
 	URL               string    `json:"url,omitempty"`
 	URLExpiresAt      *DateTime `json:"url_expires_at,omitempty"`
@@ -36,9 +36,11 @@ type ListReportsResult struct {
 // available, the report can be downloaded from the provided URL for 30 seconds.
 //
 //	c := easypost.New(MyEasyPostAPIKey)
+//	start := easypost.NewDate(2016, time.October, 1)
+//	end := easypost.NewDate(2016, time.October, 31)
 //	c.CreateReport(
 //		"payment_log",
-//		&easypost.Report{StartDate: "2016-10-01", EndDate: "2016-10-31"},
+//		&easypost.Report{StartDate: &start, EndDate: &end},
 //	)
 func (c *Client) CreateReport(typ string, in *Report) (out *Report, err error) {
 	return c.CreateReportWithContext(context.Background(), typ, in)
@@ -60,6 +62,9 @@ func (c *Client) ListReports(typ string, opts *ListOptions) (out *ListReportsRes
 // specifying a context that can interrupt the request.
 func (c *Client) ListReportsWithContext(ctx context.Context, typ string, opts *ListOptions) (out *ListReportsResult, err error) {
 	err = c.do(ctx, http.MethodGet, "reports/"+typ, c.convertOptsToURLValues(opts), &out)
+	if err != nil {
+		return
+	}
 	// Store the original query parameters for reuse when getting the next page
 	out.Type = typ
 	return
@@ -113,3 +118,35 @@ func (c *Client) GetReportWithContext(ctx context.Context, typ, reportID string)
 	err = c.get(ctx, "reports/"+typ+"/"+reportID, &out)
 	return
 }
+
+// listReportsOptions are the options of a ListIterator over reports.
+type listReportsOptions struct {
+	Type    string       `json:"type"`
+	Options *ListOptions `json:"options"`
+}
+
+// IterateReports returns a ListIterator over the reports of the given type matching the given options, whose items
+// are of type *Report.
+func (c *Client) IterateReports(typ string, opts *ListOptions) *ListIterator {
+	return c.IterateReportsWithContext(context.Background(), typ, opts)
+}
+
+// IterateReportsWithContext performs the same operation as IterateReports, but allows specifying a context that can
+// interrupt the requests of the iterator.
+func (c *Client) IterateReportsWithContext(ctx context.Context, typ string, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "reports", &listReportsOptions{Type: typ, Options: copyListOptions(opts)})
+}
+
+// listReportsPage fetches a page of reports for a ListIterator.
+func listReportsPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	opts := options.(*listReportsOptions)
+	result, err := c.ListReportsWithContext(ctx, opts.Type, pageListOptions(opts.Options, beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, report := range result.Reports {
+		page.add(report, report.ID)
+	}
+	return page, nil
+}
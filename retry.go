@@ -0,0 +1,188 @@
+package easypost
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy configures how a Client retries requests that failed with a transient error.
+//
+// Only requests that are safe to replay are retried: GET, HEAD, OPTIONS, PUT and DELETE requests, as well as purchase
+// requests sent with an idempotency key (see WithIdempotencyKey).
+// A request is retried when it fails with an error whose Retryable method returns true, e.g. when the connection to
+// the API fails, or when the API returns a 408, 429, 502, 503 or 504 status code (see APIError.Retryable).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a request, including the first one.
+	// Values of 1 or less disable retries.
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry. The delay doubles with each subsequent retry.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, including delays requested by a Retry-After header.
+	// If zero, the delay is not capped.
+	MaxBackoff time.Duration
+	// Jitter randomly extends each delay by up to this fraction of its value (e.g. 0.2 for up to 20%).
+	Jitter float64
+	// RespectRetryAfter makes the Client wait for the delay given in a Retry-After response header, if present,
+	// instead of the computed backoff.
+	RespectRetryAfter bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with sensible defaults: up to 3 attempts, exponential backoff starting
+// at 500 milliseconds and capped at 10 seconds, 20% jitter, and honoring the Retry-After header.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       3,
+		BaseBackoff:       500 * time.Millisecond,
+		MaxBackoff:        10 * time.Second,
+		Jitter:            0.2,
+		RespectRetryAfter: true,
+	}
+}
+
+// jitterRand is the random source used to compute backoff jitter; rand.Rand is not safe for concurrent use.
+var jitterRand = rand.New(rand.NewSource(time.Now().UnixNano())) // nolint:gosec
+var jitterRandMutex sync.Mutex
+
+// maxAttempts returns the total number of attempts allowed by the policy.
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry returns true if a request that completed its given attempt with the given response or transport error
+// should be attempted again.
+func (p *RetryPolicy) shouldRetry(req *http.Request, attempt int, res *http.Response, err error) bool {
+	if attempt >= p.maxAttempts() {
+		return false
+	}
+	if !isReplayableRequest(req) {
+		return false
+	}
+	if err != nil {
+		// e.g. certificate errors, which would fail again
+		if retryable, ok := err.(interface{ Retryable() bool }); ok && !retryable.Retryable() {
+			return false
+		}
+		// the caller gave up, retrying would be pointless
+		return req.Context().Err() == nil
+	}
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		return false
+	}
+	return isRetryableResponse(res)
+}
+
+// backoff returns how long to wait after the given (failed) attempt before making the next one.
+func (p *RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if p.RespectRetryAfter && res != nil {
+		if delay, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			return p.capBackoff(delay)
+		}
+	}
+
+	delay := time.Duration(float64(p.BaseBackoff) * math.Pow(2, float64(attempt-1)))
+	if p.Jitter > 0 {
+		jitterRandMutex.Lock()
+		delay += time.Duration(jitterRand.Float64() * p.Jitter * float64(delay))
+		jitterRandMutex.Unlock()
+	}
+	return p.capBackoff(delay)
+}
+
+// capBackoff limits the given delay to the policy's MaxBackoff.
+func (p *RetryPolicy) capBackoff(delay time.Duration) time.Duration {
+	// guard against overflows of the exponential computation
+	if delay < 0 || (p.MaxBackoff > 0 && delay > p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+// isReplayableRequest returns true if the request uses an idempotent method or carries an idempotency key, and its
+// body (if any) can be re-read.
+func isReplayableRequest(req *http.Request) bool {
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+	if req.Header.Get(IdempotencyKeyHeader) != "" {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableResponse returns true if the error built from the given unsuccessful response is retryable. The body of
+// the response is buffered, so that it can still be read afterwards.
+func isRetryableResponse(res *http.Response) bool {
+	body, err := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		res.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), &errorReader{err: err}))
+		return false
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	buffered := *res
+	buffered.Body = ioutil.NopCloser(bytes.NewReader(body))
+	retryable, ok := buildErrorFromResponse(&buffered, defaultRedactor).(interface{ Retryable() bool })
+	return ok && retryable.Retryable()
+}
+
+// errorReader returns the error a body could not be read with, once the part that was read has been returned.
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which can either be a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleepWithContext waits for the given duration, returning early with the context's error if it is done first.
+func sleepWithContext(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -110,3 +110,28 @@ func (c *Client) GetScanFormWithContext(ctx context.Context, scanFormID string)
 	err = c.get(ctx, "scan_forms/"+scanFormID, &out)
 	return
 }
+
+// IterateScanForms returns a ListIterator over the scan forms matching the given options, whose items are of type
+// *ScanForm.
+func (c *Client) IterateScanForms(opts *ListOptions) *ListIterator {
+	return c.IterateScanFormsWithContext(context.Background(), opts)
+}
+
+// IterateScanFormsWithContext performs the same operation as IterateScanForms, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateScanFormsWithContext(ctx context.Context, opts *ListOptions) *ListIterator {
+	return c.newListIterator(ctx, "scan_forms", copyListOptions(opts))
+}
+
+// listScanFormsPage fetches a page of scan forms for a ListIterator.
+func listScanFormsPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	result, err := c.ListScanFormsWithContext(ctx, pageListOptions(options.(*ListOptions), beforeID))
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, scanForm := range result.ScanForms {
+		page.add(scanForm, scanForm.ID)
+	}
+	return page, nil
+}
@@ -25,7 +25,7 @@ type PostageLabel struct {
 	CreatedAt       *DateTime `json:"created_at,omitempty"`
 	UpdatedAt       *DateTime `json:"updated_at,omitempty"`
 	IntegratedForm  string    `json:"integrated_form,omitempty"`
-	LabelDate       *DateTime `json:"label_date,omitempty"`
+	LabelDate       *Date     `json:"label_date,omitempty"`
 	LabelEPL2URL    string    `json:"label_epl2_url,omitempty"`
 	LabelFileType   string    `json:"label_file_type,omitempty"`
 	LabelPDFURL     string    `json:"label_pdf_url,omitempty"`
@@ -195,6 +195,9 @@ func (c *Client) ListShipments(opts *ListShipmentsOptions) (out *ListShipmentsRe
 // allows specifying a context that can interrupt the request.
 func (c *Client) ListShipmentsWithContext(ctx context.Context, opts *ListShipmentsOptions) (out *ListShipmentsResult, err error) {
 	err = c.do(ctx, http.MethodGet, "shipments", c.convertOptsToURLValues(opts), &out)
+	if err != nil {
+		return
+	}
 	// Store the original query parameters for reuse when getting the next page
 	out.Purchased = opts.Purchased
 	out.IncludeChildren = opts.IncludeChildren
@@ -253,7 +256,7 @@ func (c *Client) GetShipmentWithContext(ctx context.Context, shipmentID string)
 }
 
 func (c *Client) buyShipment(ctx context.Context, shipmentID string, in *buyShipmentRequest) (out *Shipment, err error) {
-	err = c.post(ctx, "shipments/"+shipmentID+"/buy", &in, &out)
+	err = c.purchase(ctx, "shipments/"+shipmentID+"/buy", &in, &out, plannedRate(in.Rate, in.Insurance))
 	return
 }
 
@@ -350,6 +353,9 @@ func (c *Client) InsureShipment(shipmentID, amount string) (out *Shipment, err e
 // InsureShipmentWithContext performs the same operation as InsureShipment, but
 // allows specifying a context that can interrupt the request.
 func (c *Client) InsureShipmentWithContext(ctx context.Context, shipmentID, amount string) (out *Shipment, err error) {
+	if err = c.SpendingPolicy.checkInsurance(amount); err != nil {
+		return
+	}
 	vals := url.Values{"amount": []string{amount}}
 	err = c.post(ctx, "shipments/"+shipmentID+"/insure", vals, &out)
 	return
@@ -458,3 +464,36 @@ func (c *Client) GetShipmentEstimatedDeliveryDateWithContext(ctx context.Context
 	err = c.do(ctx, http.MethodGet, "shipments/"+shipmentID+"/smartrate/delivery_date", vals, &res)
 	return
 }
+
+// IterateShipments returns a ListIterator over the shipments matching the given options, whose items are of type
+// *Shipment.
+func (c *Client) IterateShipments(opts *ListShipmentsOptions) *ListIterator {
+	return c.IterateShipmentsWithContext(context.Background(), opts)
+}
+
+// IterateShipmentsWithContext performs the same operation as IterateShipments, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateShipmentsWithContext(ctx context.Context, opts *ListShipmentsOptions) *ListIterator {
+	options := ListShipmentsOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	return c.newListIterator(ctx, "shipments", &options)
+}
+
+// listShipmentsPage fetches a page of shipments for a ListIterator.
+func listShipmentsPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	opts := *options.(*ListShipmentsOptions)
+	if beforeID != "" {
+		opts.BeforeID = beforeID
+	}
+	result, err := c.ListShipmentsWithContext(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, shipment := range result.Shipments {
+		page.add(shipment, shipment.ID)
+	}
+	return page, nil
+}
@@ -0,0 +1,191 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults of ShipmentWindowOptions.
+const (
+	DefaultShipmentWindow            = 24 * time.Hour
+	DefaultShipmentWindowConcurrency = 4
+)
+
+// ShipmentWindowOptions specifies the shipments listed by ListShipmentsInWindows, and how the listing is split.
+type ShipmentWindowOptions struct {
+	// StartDateTime and EndDateTime delimit the creation dates of the listed shipments. Both are required.
+	StartDateTime *DateTime
+	EndDateTime   *DateTime
+	// Window is the length of the date windows listed concurrently. If zero, DefaultShipmentWindow is used.
+	Window time.Duration
+	// Concurrency is the maximum number of windows listed at the same time. If zero,
+	// DefaultShipmentWindowConcurrency is used.
+	Concurrency     int
+	PageSize        int
+	Purchased       *bool
+	IncludeChildren *bool
+}
+
+// shipmentWindowParams are the parameters of a request listing a page of the shipments of a window. The dates of the
+// window are formatted here rather than by DateTime, so that their fractional seconds and offset are always sent.
+type shipmentWindowParams struct {
+	BeforeID        string `url:"before_id,omitempty"`
+	StartDateTime   string `url:"start_datetime"`
+	EndDateTime     string `url:"end_datetime"`
+	PageSize        int    `url:"page_size,omitempty"`
+	Purchased       *bool  `url:"purchased,omitempty"`
+	IncludeChildren *bool  `url:"include_children,omitempty"`
+}
+
+// shipmentWindow is a date window listed by ListShipmentsInWindows, whose shipments are streamed through a channel.
+type shipmentWindow struct {
+	start     time.Time
+	end       time.Time
+	shipments chan *Shipment
+	// err is set before shipments is closed
+	err error
+}
+
+// ListShipmentsInWindows lists the shipments created between opts.StartDateTime and opts.EndDateTime, calling fn with
+// each of them, e.g. for large exports. The date range is split into windows of opts.Window, which are paged through
+// concurrently. Shipments are passed to fn one at a time, and only a few pages are held in memory at any time.
+//
+// Shipments are not sorted by ID: windows are passed in turn from the newest, and the shipments of a window in the
+// order returned by the API, newest first as with ListShipments. A shipment created at the edge of two windows is
+// listed by both, but only passed to fn once.
+//
+// Listing stops at the first error, including an error returned by fn, which is returned.
+func (c *Client) ListShipmentsInWindows(opts *ShipmentWindowOptions, fn func(shipment *Shipment) error) error {
+	return c.ListShipmentsInWindowsWithContext(context.Background(), opts, fn)
+}
+
+// ListShipmentsInWindowsWithContext performs the same operation as ListShipmentsInWindows, but allows specifying a
+// context that can interrupt the requests.
+func (c *Client) ListShipmentsInWindowsWithContext(ctx context.Context, opts *ShipmentWindowOptions, fn func(shipment *Shipment) error) error {
+	if opts == nil || opts.StartDateTime == nil {
+		return newMissingPropertyError("StartDateTime")
+	}
+	if opts.EndDateTime == nil {
+		return newMissingPropertyError("EndDateTime")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	// stops the windows still being listed when returning early
+	defer cancel()
+
+	windows := splitShipmentWindows(opts)
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultShipmentWindowConcurrency
+	}
+
+	// windows are started in order, so the window being read always holds one of the slots
+	go func() {
+		slots := make(chan struct{}, concurrency)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		for _, window := range windows {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(window *shipmentWindow) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				c.listShipmentWindow(ctx, opts, window)
+			}(window)
+		}
+	}()
+
+	var previous, current map[string]bool
+	for _, window := range windows {
+		// shipments created at the edge of two windows are listed in both
+		previous, current = current, make(map[string]bool)
+		for {
+			var shipment *Shipment
+			var ok bool
+			select {
+			case shipment, ok = <-window.shipments:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if !ok {
+				break
+			}
+			if previous[shipment.ID] || current[shipment.ID] {
+				continue
+			}
+			current[shipment.ID] = true
+			if err := fn(shipment); err != nil {
+				return err
+			}
+		}
+		if window.err != nil {
+			return window.err
+		}
+	}
+	return nil
+}
+
+// splitShipmentWindows splits the date range of the given options into windows, newest first.
+func splitShipmentWindows(opts *ShipmentWindowOptions) []*shipmentWindow {
+	length := opts.Window
+	if length <= 0 {
+		length = DefaultShipmentWindow
+	}
+	bufferSize := opts.PageSize
+	if bufferSize <= 0 {
+		bufferSize = 20
+	}
+
+	start := opts.StartDateTime.AsTime()
+	var windows []*shipmentWindow
+	for end := opts.EndDateTime.AsTime(); ; end = end.Add(-length) {
+		windowStart := end.Add(-length)
+		if !windowStart.After(start) {
+			windowStart = start
+		}
+		windows = append(windows, &shipmentWindow{start: windowStart, end: end, shipments: make(chan *Shipment, bufferSize)})
+		if !windowStart.After(start) {
+			return windows
+		}
+	}
+}
+
+// listShipmentWindow pages through the shipments of a window, sending them to its channel.
+func (c *Client) listShipmentWindow(ctx context.Context, opts *ShipmentWindowOptions, window *shipmentWindow) {
+	defer close(window.shipments)
+
+	params := &shipmentWindowParams{
+		StartDateTime:   window.start.Format(time.RFC3339Nano),
+		EndDateTime:     window.end.Format(time.RFC3339Nano),
+		PageSize:        opts.PageSize,
+		Purchased:       opts.Purchased,
+		IncludeChildren: opts.IncludeChildren,
+	}
+	for {
+		var page ListShipmentsResult
+		if err := c.do(ctx, http.MethodGet, "shipments", c.convertOptsToURLValues(params), &page); err != nil {
+			window.err = err
+			return
+		}
+		for _, shipment := range page.Shipments {
+			select {
+			case window.shipments <- shipment:
+			case <-ctx.Done():
+				window.err = ctx.Err()
+				return
+			}
+		}
+		if !page.HasMore || len(page.Shipments) == 0 {
+			return
+		}
+		params.BeforeID = page.Shipments[len(page.Shipments)-1].ID
+	}
+}
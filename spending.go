@@ -0,0 +1,304 @@
+package easypost
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpendingRule identifies the rule of a SpendingPolicy that a purchase violates.
+type SpendingRule string
+
+// Rules of a SpendingPolicy.
+const (
+	// SpendingRuleMaxRate is violated by a label whose rate exceeds MaxRate.
+	SpendingRuleMaxRate SpendingRule = "max_rate"
+	// SpendingRuleMaxInsurance is violated by an insurance amount exceeding MaxInsurance.
+	SpendingRuleMaxInsurance SpendingRule = "max_insurance"
+	// SpendingRuleDailyLimit is violated by a purchase that would bring the spend of the day above DailyLimit.
+	SpendingRuleDailyLimit SpendingRule = "daily_limit"
+	// SpendingRuleAllowedServices is violated by a carrier or service missing from AllowedServices.
+	SpendingRuleAllowedServices SpendingRule = "allowed_services"
+	// SpendingRuleUnknownRate is violated by a label whose rate is needed to enforce MaxRate or DailyLimit but is
+	// unknown, e.g. a Rate given by its ID only.
+	SpendingRuleUnknownRate SpendingRule = "unknown_rate"
+)
+
+// SpendingPolicy limits the purchases made by a Client (via its SpendingPolicy property). Purchases violating the
+// policy are refused with a SpendingPolicyError before any request is sent. The policy applies to BuyShipment (and
+// its variants), InsureShipment, BuyOrder, BuyPickup, BuyBatch and CreateAndBuyBatch.
+//
+// Amounts are in the currency of the rates, and a zero limit means no limit. The rate of a purchase is reserved in the
+// spend of the day before it is sent, so that concurrent purchases cannot exceed DailyLimit together. It is released
+// if the purchase is not sent or the API rejects it, and kept if the outcome is unknown, e.g. when the connection
+// fails after sending it. Once bought, the reservation is replaced by the postage actually paid, if the response holds
+// it.
+//
+// The rates of an order are looked up in the order before it is bought, and those of a batch created by
+// CreateAndBuyBatch in the Rates of its shipments, if any. A rate that cannot be found this way is only known once
+// bought: the purchase is then refused if MaxRate is set, and otherwise once the daily limit has been reached. The
+// labels of a batch bought by BuyBatch are not known beforehand, so its purchase is only refused once the daily limit
+// has been reached; batches are bought asynchronously, so their postage is not tracked.
+type SpendingPolicy struct {
+	// MaxRate is the maximum rate of a single label.
+	MaxRate float64
+	// MaxInsurance is the maximum insurance amount of a single shipment.
+	MaxInsurance float64
+	// DailyLimit is the maximum postage spent per day, as tracked by Store.
+	DailyLimit float64
+	// AllowedServices lists the services that may be bought, keyed by carrier (e.g. "USPS"). A carrier mapped to an
+	// empty list allows all its services. If nil, all carriers and services are allowed.
+	AllowedServices map[string][]string
+	// Store tracks the postage spent per day. If nil, it is kept in memory by the policy.
+	Store SpendingStore
+	// Location sets when days start and end for DailyLimit. If nil, UTC is used.
+	Location *time.Location
+
+	storeOnce    sync.Once
+	defaultStore SpendingStore
+}
+
+// SpendingStore tracks the postage spent per day for the DailyLimit of a SpendingPolicy, e.g. in a database shared by
+// several processes. Implementations must be safe for concurrent use.
+type SpendingStore interface {
+	// Spent returns the amount spent on the given day, formatted as YYYY-MM-DD.
+	Spent(ctx context.Context, day string) (float64, error)
+	// AddSpent adds an amount to the amount spent on the given day, formatted as YYYY-MM-DD. The amount is negative
+	// when a reservation is released or exceeded the postage actually paid.
+	AddSpent(ctx context.Context, day string, amount float64) error
+	// Reserve atomically adds an amount to the amount spent on the given day, formatted as YYYY-MM-DD, unless the
+	// limit has already been reached or would be exceeded. It returns the amount spent before, and whether the amount
+	// was added.
+	Reserve(ctx context.Context, day string, amount, limit float64) (spent float64, ok bool, err error)
+}
+
+// InMemorySpendingStore is a SpendingStore keeping the spend of each day in memory.
+type InMemorySpendingStore struct {
+	mutex sync.Mutex
+	spent map[string]float64
+}
+
+// NewInMemorySpendingStore returns a new, empty InMemorySpendingStore.
+func NewInMemorySpendingStore() *InMemorySpendingStore {
+	return &InMemorySpendingStore{spent: make(map[string]float64)}
+}
+
+// Spent returns the amount spent on the given day.
+func (s *InMemorySpendingStore) Spent(_ context.Context, day string) (float64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.spent[day], nil
+}
+
+// AddSpent adds an amount to the amount spent on the given day.
+func (s *InMemorySpendingStore) AddSpent(_ context.Context, day string, amount float64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.spent[day] += amount
+	return nil
+}
+
+// Reserve adds an amount to the amount spent on the given day, unless the limit has been reached or would be exceeded.
+func (s *InMemorySpendingStore) Reserve(_ context.Context, day string, amount, limit float64) (float64, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	spent := s.spent[day]
+	if spent >= limit || spent+amount > limit {
+		return spent, false, nil
+	}
+	s.spent[day] = spent + amount
+	return spent, true, nil
+}
+
+// plannedPurchase describes a label about to be bought, as checked against the SpendingPolicy.
+type plannedPurchase struct {
+	carrier   string
+	service   string
+	rate      string
+	insurance string
+	// unpriced is set when the rate may only be known once bought, e.g. for orders.
+	unpriced bool
+	// unknown is set when the labels are not known before the purchase, e.g. for BuyBatch: only DailyLimit applies.
+	unknown bool
+}
+
+// spendingReservation is the amount reserved by a purchase in the spend of a day, until it is settled or released.
+type spendingReservation struct {
+	day    string
+	amount float64
+}
+
+// plannedRate returns the plannedPurchase of a label bought at the given rate.
+func plannedRate(rate *Rate, insurance string) plannedPurchase {
+	if rate == nil {
+		return plannedPurchase{insurance: insurance}
+	}
+	return plannedPurchase{carrier: rate.Carrier, service: rate.Service, rate: rate.Rate, insurance: insurance}
+}
+
+// plannedService returns the plannedPurchase of a label bought with the given carrier and service, whose rate is
+// looked up in the given rates. The rate is only known once bought if it is not found.
+func plannedService(rates []*Rate, carrier, service, insurance string) plannedPurchase {
+	purchase := plannedPurchase{carrier: carrier, service: service, insurance: insurance, unpriced: true}
+	for _, rate := range rates {
+		if rate != nil && strings.EqualFold(rate.Carrier, carrier) && strings.EqualFold(rate.Service, service) {
+			purchase.rate = rate.Rate
+			break
+		}
+	}
+	return purchase
+}
+
+// needsRates returns true if the policy needs the rates of the labels being bought, e.g. to look up those of an order.
+func (p *SpendingPolicy) needsRates() bool {
+	return p != nil && (p.MaxRate > 0 || p.DailyLimit > 0)
+}
+
+// store returns the SpendingStore of the policy.
+func (p *SpendingPolicy) store() SpendingStore {
+	if p.Store != nil {
+		return p.Store
+	}
+	p.storeOnce.Do(func() {
+		p.defaultStore = NewInMemorySpendingStore()
+	})
+	return p.defaultStore
+}
+
+// today returns the current day, formatted as YYYY-MM-DD in the Location of the policy.
+func (p *SpendingPolicy) today() string {
+	location := p.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return time.Now().In(location).Format("2006-01-02")
+}
+
+// checkInsurance returns a SpendingPolicyError if the given insurance amount exceeds MaxInsurance.
+func (p *SpendingPolicy) checkInsurance(insurance string) error {
+	if p == nil || p.MaxInsurance <= 0 || insurance == "" {
+		return nil
+	}
+	amount, err := strconv.ParseFloat(insurance, 64)
+	if err != nil || amount > p.MaxInsurance {
+		return newSpendingPolicyError(SpendingRuleMaxInsurance, "insurance of "+insurance+" exceeds "+formatAmount(p.MaxInsurance))
+	}
+	return nil
+}
+
+// checkAllowed returns a SpendingPolicyError if the given carrier or service are not in AllowedServices.
+func (p *SpendingPolicy) checkAllowed(carrier, service string) error {
+	if p.AllowedServices == nil {
+		return nil
+	}
+	services, ok := p.AllowedServices[carrier]
+	if !ok {
+		return newSpendingPolicyError(SpendingRuleAllowedServices, "carrier "+strconv.Quote(carrier)+" is not allowed")
+	}
+	if len(services) == 0 {
+		return nil
+	}
+	for _, allowed := range services {
+		if allowed == service {
+			return nil
+		}
+	}
+	return newSpendingPolicyError(SpendingRuleAllowedServices, "service "+strconv.Quote(service)+" of "+carrier+" is not allowed")
+}
+
+// check returns a SpendingPolicyError if the given purchases violate the rules of the policy other than DailyLimit,
+// along with the total of their known rates.
+func (p *SpendingPolicy) check(purchases []plannedPurchase) (float64, error) {
+	total := 0.0
+	for _, purchase := range purchases {
+		if purchase.unknown {
+			continue
+		}
+		if err := p.checkAllowed(purchase.carrier, purchase.service); err != nil {
+			return 0, err
+		}
+		if err := p.checkInsurance(purchase.insurance); err != nil {
+			return 0, err
+		}
+		if !p.needsRates() {
+			continue
+		}
+		rate, err := strconv.ParseFloat(purchase.rate, 64)
+		if err != nil {
+			if p.MaxRate > 0 || !purchase.unpriced {
+				return 0, newSpendingPolicyError(SpendingRuleUnknownRate, "the rate of the label is unknown")
+			}
+			continue
+		}
+		if p.MaxRate > 0 && rate > p.MaxRate {
+			return 0, newSpendingPolicyError(SpendingRuleMaxRate, "rate of "+purchase.rate+" exceeds "+formatAmount(p.MaxRate))
+		}
+		total += rate
+	}
+	return total, nil
+}
+
+// reserve returns a SpendingPolicyError if the given purchases violate the policy, or else reserves the total of their
+// rates in the spend of the day. It returns the error of the Store if the reservation cannot be made.
+func (p *SpendingPolicy) reserve(ctx context.Context, purchases []plannedPurchase) (*spendingReservation, error) {
+	if p == nil || len(purchases) == 0 {
+		return nil, nil
+	}
+	total, err := p.check(purchases)
+	if err != nil {
+		return nil, err
+	}
+	if p.DailyLimit <= 0 {
+		return nil, nil
+	}
+
+	day := p.today()
+	spent, ok, err := p.store().Reserve(ctx, day, total, p.DailyLimit)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, newSpendingPolicyError(SpendingRuleDailyLimit, "spend of "+formatAmount(spent+total)+" would exceed the daily limit of "+formatAmount(p.DailyLimit))
+	}
+	return &spendingReservation{day: day, amount: total}, nil
+}
+
+// release removes the reservation of a purchase that was not made from the spend of its day.
+func (p *SpendingPolicy) release(ctx context.Context, reservation *spendingReservation) {
+	if reservation == nil || reservation.amount == 0 {
+		return
+	}
+	// the context of the purchase may be done, the reservation must be released anyway
+	_ = p.store().AddSpent(detachedContext{parent: ctx}, reservation.day, -reservation.amount)
+}
+
+// settle replaces the reservation of a purchase by the postage of the labels it bought, taken from the bought shipments
+// in out. The reservation stands if there are none, e.g. for pickups.
+func (p *SpendingPolicy) settle(ctx context.Context, reservation *spendingReservation, out interface{}) {
+	if reservation == nil {
+		return
+	}
+
+	spent := 0.0
+	found := false
+	for _, shipment := range purchasedShipments(out) {
+		if purchase, ok := labelPurchaseOf(shipment); ok {
+			spent += purchase.Postage
+			found = true
+		}
+	}
+	if found && spent != reservation.amount {
+		// the purchase has been made; failing to track it must not turn it into an error, even if its context is done
+		_ = p.store().AddSpent(detachedContext{parent: ctx}, reservation.day, spent-reservation.amount)
+	}
+}
+
+// formatAmount formats an amount of money for an error message.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
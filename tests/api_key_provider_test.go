@@ -0,0 +1,131 @@
+package easypost_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+// basicAuth returns the Authorization header sent for the given API key
+func basicAuth(apiKey string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(apiKey+":"))
+}
+
+func (c *ClientTests) TestAPIKeyProviders() {
+	assert, require := c.Assert(), c.Require()
+
+	key, err := easypost.StaticAPIKey("EZAK_STATIC").GetAPIKey(context.Background())
+	require.NoError(err)
+	assert.Equal("EZAK_STATIC", key)
+
+	require.NoError(os.Setenv("EASYPOST_TEST_API_KEY", " EZAK_ENV\n"))
+	defer func() { _ = os.Unsetenv("EASYPOST_TEST_API_KEY") }()
+	key, err = easypost.EnvAPIKey("EASYPOST_TEST_API_KEY").GetAPIKey(context.Background())
+	require.NoError(err)
+	assert.Equal("EZAK_ENV", key)
+
+	dir, err := ioutil.TempDir("", "easypost")
+	require.NoError(err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "api_key")
+	require.NoError(ioutil.WriteFile(path, []byte("EZAK_FILE_1\n"), 0600))
+
+	provider := easypost.NewFileAPIKey(path)
+	key, err = provider.GetAPIKey(context.Background())
+	require.NoError(err)
+	assert.Equal("EZAK_FILE_1", key)
+
+	// the file is read again once it changes
+	require.NoError(ioutil.WriteFile(path, []byte("EZAK_FILE_22\n"), 0600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(os.Chtimes(path, later, later))
+	key, err = provider.GetAPIKey(context.Background())
+	require.NoError(err)
+	assert.Equal("EZAK_FILE_22", key)
+
+	_, err = easypost.NewFileAPIKey(filepath.Join(dir, "missing")).GetAPIKey(context.Background())
+	assert.True(os.IsNotExist(err))
+}
+
+func (c *ClientTests) TestAPIKeyProviderPerRequest() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.APIKey = ""
+
+	require.NoError(os.Setenv("EASYPOST_TEST_API_KEY", "EZAK_1"))
+	defer func() { _ = os.Unsetenv("EASYPOST_TEST_API_KEY") }()
+	client.APIKeyProvider = easypost.EnvAPIKey("EASYPOST_TEST_API_KEY")
+
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+	require.NoError(os.Setenv("EASYPOST_TEST_API_KEY", "EZAK_2"))
+	_, err = client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal(basicAuth("EZAK_1"), transport.Requests[0].Header.Get("Authorization"))
+	assert.Equal(basicAuth("EZAK_2"), transport.Requests[1].Header.Get("Authorization"))
+
+	// an empty key is still reported as missing
+	require.NoError(os.Setenv("EASYPOST_TEST_API_KEY", ""))
+	_, err = client.GetAddress("adr_123")
+	_, ok := err.(*easypost.MissingPropertyError)
+	assert.True(ok)
+}
+
+func (c *ClientTests) TestAPIKeyFallback() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 401, Body: `{"error": {"code": "APIKEY.INACTIVE", "message": "This api key is no longer active."}}`},
+			{StatusCode: 200, Body: `{"id": "shp_123"}`},
+			{StatusCode: 401},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.APIKeyProvider = easypost.StaticAPIKey("EZAK_PRIMARY")
+	client.FallbackAPIKeyProvider = easypost.StaticAPIKey("EZAK_SECONDARY")
+
+	var attempts []int
+	client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+			attempts = append(attempts, event.Attempt)
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+
+	shipment, err := client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+	assert.Equal("shp_123", shipment.ID)
+
+	require.Equal(2, len(transport.Requests))
+	assert.Equal(basicAuth("EZAK_PRIMARY"), transport.Requests[0].Header.Get("Authorization"))
+	assert.Equal(basicAuth("EZAK_SECONDARY"), transport.Requests[1].Header.Get("Authorization"))
+	// the fallback request is the same purchase
+	assert.Equal(transport.Bodies[0], transport.Bodies[1])
+	assert.Equal(transport.Requests[0].Header.Get(easypost.IdempotencyKeyHeader), transport.Requests[1].Header.Get(easypost.IdempotencyKeyHeader))
+	assert.Equal([]int{1, 2}, attempts)
+
+	// the fallback key is tried only once
+	_, err = client.GetShipment("shp_123")
+	_, ok := err.(*easypost.UnauthorizedError)
+	assert.True(ok)
+	assert.Equal(4, len(transport.Requests))
+
+	// keys set for a call are never replaced
+	ctx := easypost.WithCallOptions(context.Background(), easypost.CallAPIKey("EZAK_CUSTOMER"))
+	_, err = client.GetShipmentWithContext(ctx, "shp_123")
+	_, ok = err.(*easypost.UnauthorizedError)
+	assert.True(ok)
+	assert.Equal(5, len(transport.Requests))
+}
@@ -159,3 +159,27 @@ func (c *ClientTests) TestBatchLabel() {
 	// We can't assert anything meaningful here because the label gets queued for generation and may not be immediately available
 	assert.Equal(reflect.TypeOf(&easypost.Batch{}), reflect.TypeOf(batchWithLabel))
 }
+
+func (c *ClientTests) TestBatchGetNextPage() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"batches": [{"id": "batch_3"}, {"id": "batch_2"}], "has_more": true}`},
+			{StatusCode: 200, Body: `{"batches": [{"id": "batch_1"}], "has_more": false}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	batches, err := client.ListBatches(&easypost.ListOptions{PageSize: 2})
+	require.NoError(err)
+	nextPage, err := client.GetNextBatchPageWithPageSize(batches, 2)
+	require.NoError(err)
+	assert.Equal("batch_1", nextPage.Batch[0].ID)
+	assert.Equal("batch_2", listParameters(transport.Bodies[1]).Get("before_id"))
+	assert.Equal("2", listParameters(transport.Bodies[1]).Get("page_size"))
+
+	_, err = client.GetNextBatchPage(nextPage)
+	assert.Equal(easypost.EndOfPaginationError, err)
+	assert.Equal(2, len(transport.Requests))
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -36,6 +37,56 @@ func (ErrorRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	return nil, fmt.Errorf("no cassette found for request %s", req.URL)
 }
 
+// ScriptedResponse is a canned response (or transport error) returned by a ScriptedRoundTripper.
+type ScriptedResponse struct {
+	StatusCode int
+	Body       string
+	Header     http.Header
+	Err        error
+}
+
+// ScriptedRoundTripper returns its responses in order (repeating the last one once exhausted) and records every request body it receives.
+// It is safe for concurrent use.
+type ScriptedRoundTripper struct {
+	Responses []ScriptedResponse
+	Requests  []*http.Request
+	Bodies    []string
+	mutex     sync.Mutex
+}
+
+func (s *ScriptedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		buf, _ := ioutil.ReadAll(req.Body)
+		body = string(buf)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Requests = append(s.Requests, req)
+	s.Bodies = append(s.Bodies, body)
+
+	index := len(s.Requests) - 1
+	if index >= len(s.Responses) {
+		index = len(s.Responses) - 1
+	}
+	scripted := s.Responses[index]
+	if scripted.Err != nil {
+		return nil, scripted.Err
+	}
+	header := scripted.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:     http.StatusText(scripted.StatusCode),
+		StatusCode: scripted.StatusCode,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(scripted.Body)),
+		Request:    req,
+	}, nil
+}
+
 type ClientTests struct {
 	suite.Suite
 	recorder *recorder.Recorder
@@ -305,6 +356,14 @@ func (c *ClientTests) MockClient(requests []easypost.MockRequest) *easypost.Clie
 	}
 }
 
+// ScriptedClient sets up a client object whose requests are answered by the given ScriptedRoundTripper
+func (c *ClientTests) ScriptedClient(transport *ScriptedRoundTripper) *easypost.Client {
+	return &easypost.Client{
+		APIKey: "cannot_be_blank",
+		Client: &http.Client{Transport: transport},
+	}
+}
+
 // TestClient runs the entire test suite
 func TestClient(t *testing.T) {
 	suite.Run(t, new(ClientTests))
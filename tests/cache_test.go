@@ -0,0 +1,172 @@
+package easypost_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+func (c *ClientTests) TestResponseCache() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `[{"id": "ca_1", "description": "first"}]`},
+			{StatusCode: 200, Body: `{"id": "rate_1", "rate": "7.58"}`},
+			{StatusCode: 200, Body: `[{"id": "ca_1", "description": "other key"}]`},
+			{StatusCode: 200, Body: `{"id": "ca_1", "description": "updated"}`},
+			{StatusCode: 200, Body: `[{"id": "ca_1", "description": "updated"}]`},
+			{StatusCode: 200, Body: `{"id": "adr_1"}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.Cache = easypost.NewResponseCache()
+
+	accounts, err := client.ListCarrierAccounts()
+	require.NoError(err)
+	accounts[0].Description = "changed by the caller"
+	accounts, err = client.ListCarrierAccounts()
+	require.NoError(err)
+	assert.Equal("first", accounts[0].Description)
+	for i := 0; i < 2; i++ {
+		rate, err := client.GetRate("rate_1")
+		require.NoError(err)
+		assert.Equal("7.58", rate.Rate)
+	}
+	assert.Equal(2, len(transport.Requests))
+
+	// responses are not shared between API keys
+	accounts, err = client.ListCarrierAccountsWithContext(easypost.WithCallOptions(context.Background(), easypost.CallAPIKey("EZAK_OTHER")))
+	require.NoError(err)
+	assert.Equal("other key", accounts[0].Description)
+
+	// changes made by the client invalidate the cached responses of the resource only
+	_, err = client.UpdateCarrierAccount(&easypost.CarrierAccount{ID: "ca_1", Description: "updated"})
+	require.NoError(err)
+	accounts, err = client.ListCarrierAccounts()
+	require.NoError(err)
+	assert.Equal("updated", accounts[0].Description)
+	_, err = client.GetRate("rate_1")
+	require.NoError(err)
+	assert.Equal(5, len(transport.Requests))
+
+	// other endpoints are not cached
+	for i := 0; i < 2; i++ {
+		_, err = client.GetAddress("adr_1")
+		require.NoError(err)
+	}
+	assert.Equal(7, len(transport.Requests))
+}
+
+func (c *ClientTests) TestResponseCacheErrorsAndExpiry() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 500, Body: `{"error": {"code": "INTERNAL_SERVER_ERROR", "message": "oops"}}`},
+			{StatusCode: 200, Body: `[{"type": "UpsAccount"}]`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.Cache = &easypost.ResponseCache{TTLs: map[string]time.Duration{"carrier_types": 50 * time.Millisecond}}
+
+	_, err := client.GetCarrierTypes()
+	require.Error(err)
+	types, err := client.GetCarrierTypes()
+	require.NoError(err)
+	assert.Equal("UpsAccount", types[0].Type)
+	_, err = client.GetCarrierTypes()
+	require.NoError(err)
+	assert.Equal(2, len(transport.Requests))
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.GetCarrierTypes()
+	require.NoError(err)
+	assert.Equal(3, len(transport.Requests))
+}
+
+func (c *ClientTests) TestResponseCacheListParameters() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"addresses": [{"id": "adr_2"}], "has_more": true}`},
+			{StatusCode: 200, Body: `{"addresses": [{"id": "adr_1"}], "has_more": false}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.Cache = &easypost.ResponseCache{TTLs: map[string]time.Duration{"addresses": time.Minute}}
+
+	// the parameters of list requests are sent in their body, and are part of the cache key
+	first, err := client.ListAddresses(&easypost.ListOptions{PageSize: 1})
+	require.NoError(err)
+	assert.Equal("adr_2", first.Addresses[0].ID)
+	second, err := client.ListAddresses(&easypost.ListOptions{PageSize: 1, BeforeID: "adr_2"})
+	require.NoError(err)
+	assert.Equal("adr_1", second.Addresses[0].ID)
+	assert.Equal(2, len(transport.Requests))
+
+	cached, err := client.ListAddresses(&easypost.ListOptions{PageSize: 1})
+	require.NoError(err)
+	assert.Equal("adr_2", cached.Addresses[0].ID)
+	assert.Equal(2, len(transport.Requests))
+}
+
+func (c *ClientTests) TestResponseCacheCollapsesConcurrentRequests() {
+	assert, require := c.Assert(), c.Require()
+
+	var requests int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		_, _ = w.Write([]byte(`{"id": "user_1"}`))
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL + "/v2/")
+	client := easypost.New("cannot_be_blank", easypost.WithBaseURL(baseURL), easypost.WithCache(easypost.NewResponseCache()))
+
+	var wg sync.WaitGroup
+	users := make([]*easypost.User, 10)
+	errs := make([]error, 10)
+	for i := range users {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			users[i], errs[i] = client.RetrieveMe()
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := range users {
+		require.NoError(errs[i])
+		assert.Equal("user_1", users[i].ID)
+	}
+	assert.Equal(int32(1), atomic.LoadInt32(&requests))
+}
+
+func (c *ClientTests) TestLRUCache() {
+	assert := c.Assert()
+
+	cache := easypost.NewLRUCache(2)
+	cache.Set("a", &easypost.CachedResponse{Body: []byte("a")}, time.Minute)
+	cache.Set("b", &easypost.CachedResponse{Body: []byte("b")}, time.Minute)
+	_, ok := cache.Get("a")
+	assert.True(ok)
+	cache.Set("c", &easypost.CachedResponse{Body: []byte("c")}, time.Minute)
+
+	_, ok = cache.Get("b")
+	assert.False(ok)
+	response, ok := cache.Get("a")
+	assert.True(ok)
+	assert.Equal("a", string(response.Body))
+	assert.Equal(2, cache.Len())
+}
@@ -2,6 +2,11 @@ package easypost_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
 	"github.com/elmarw/easypost-go/v3"
 	"github.com/google/uuid"
 )
@@ -167,3 +172,243 @@ func (c *ClientTests) TestHooksUnsubscribing() {
 	// verify that the hook was not called again
 	assert.Equal(1, requestCallbackCallCount)
 }
+
+func (c *ClientTests) TestHooksReadBodies() {
+	assert, require := c.Assert(), c.Require()
+
+	responseBody := `{"id": "adr_123", "street1": "417 Montgomery Street"}`
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: responseBody}},
+	}
+	client := c.ScriptedClient(transport)
+
+	var requestBodies, responseBodies []string
+	for _, id := range []string{"hook_1", "hook_2"} {
+		client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+			Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+				// consume the body reader, the next hook and the request itself must not be affected
+				body, err := ioutil.ReadAll(event.RequestBody)
+				assert.NoError(err)
+				assert.Equal(string(event.RequestBodyBytes), string(body))
+				requestBodies = append(requestBodies, string(body))
+				return nil
+			},
+			HookEventSubscriber: easypost.HookEventSubscriber{ID: id},
+		})
+		client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+			Callback: func(ctx context.Context, event easypost.ResponseHookEvent) error {
+				body, err := ioutil.ReadAll(event.ResponseBody)
+				assert.NoError(err)
+				assert.False(event.ResponseBodyTruncated)
+				responseBodies = append(responseBodies, string(body))
+				// tampering with the bytes must not affect the deserialization either
+				for i := range event.ResponseBodyBytes {
+					event.ResponseBodyBytes[i] = 'x'
+				}
+				return nil
+			},
+			HookEventSubscriber: easypost.HookEventSubscriber{ID: id},
+		})
+	}
+
+	address, err := client.CreateAddress(&easypost.Address{Street1: "417 Montgomery Street"}, nil)
+	require.NoError(err)
+
+	assert.Equal("adr_123", address.ID)
+	assert.Equal("417 Montgomery Street", address.Street1)
+
+	require.Equal(2, len(requestBodies))
+	assert.Equal(transport.Bodies[0], requestBodies[0])
+	assert.Equal(transport.Bodies[0], requestBodies[1])
+	assert.Contains(requestBodies[0], "417 Montgomery Street")
+	assert.Equal([]string{responseBody, responseBody}, responseBodies)
+}
+
+func (c *ClientTests) TestHooksMaxBodySize() {
+	assert, require := c.Assert(), c.Require()
+
+	responseBody := `{"id": "adr_123"}`
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: responseBody}},
+	}
+	client := c.ScriptedClient(transport)
+	client.Hooks.MaxBodySize = 5
+
+	var event easypost.ResponseHookEvent
+	client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, e easypost.ResponseHookEvent) error {
+			event = e
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+
+	address, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal("adr_123", address.ID)
+	assert.Equal(responseBody[:5], string(event.ResponseBodyBytes))
+	assert.True(event.ResponseBodyTruncated)
+}
+
+// hookErrorRecorder is a HookErrorLogger that records the logged messages
+type hookErrorRecorder struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (r *hookErrorRecorder) Printf(format string, v ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.messages = append(r.messages, fmt.Sprintf(format, v...))
+}
+
+func (c *ClientTests) TestHooksPriority() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	var calls []string
+	addHook := func(id string, priority int) {
+		client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+			Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+				calls = append(calls, id)
+				return nil
+			},
+			HookEventSubscriber: easypost.HookEventSubscriber{ID: id, Priority: priority},
+		})
+	}
+	addHook("late", 10)
+	addHook("early", -5)
+	addHook("default_1", 0)
+	addHook("default_2", 0)
+
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal([]string{"early", "default_1", "default_2", "late"}, calls)
+}
+
+func (c *ClientTests) TestHooksErrorPolicies() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	errAuditFailed := errors.New("could not persist audit record")
+	client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.ResponseHookEvent) error {
+			return errAuditFailed
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "audit"},
+	})
+
+	// errors are ignored by default
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	// errors can be logged
+	recorder := &hookErrorRecorder{}
+	client.Hooks.ErrorPolicy = easypost.LogHookErrors
+	client.Hooks.ErrorLogger = recorder
+	_, err = client.GetAddress("adr_123")
+	require.NoError(err)
+	require.Equal(1, len(recorder.messages))
+	assert.Contains(recorder.messages[0], "audit")
+	assert.Contains(recorder.messages[0], errAuditFailed.Error())
+
+	// errors can abort the request
+	client.Hooks.ErrorPolicy = easypost.AbortOnHookErrors
+	_, err = client.GetAddress("adr_123")
+	require.Error(err)
+
+	hookErr, ok := err.(*easypost.HookError)
+	require.True(ok)
+	assert.Equal("audit", hookErr.HookID)
+	assert.True(errors.Is(err, errAuditFailed))
+
+	// subscribers executed directly return the error of their callback with Call
+	subscriber := client.Hooks.ResponseHookEventSubscriptions[0]
+	assert.Equal(errAuditFailed, subscriber.Call(context.Background(), easypost.ResponseHookEvent{}))
+}
+
+func (c *ClientTests) TestHooksAbortBeforeRequest() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(3)
+	client.Hooks.ErrorPolicy = easypost.AbortOnHookErrors
+	client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+			return errors.New("request vetoed")
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "veto"},
+	})
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+
+	_, ok := err.(*easypost.HookError)
+	assert.True(ok)
+	// aborted requests are neither sent nor retried
+	assert.Equal(0, len(transport.Requests))
+}
+
+func (c *ClientTests) TestHooksAsync() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.Hooks.EnableAsync(2, 10)
+	defer client.Hooks.DisableAsync()
+
+	recorder := &hookErrorRecorder{}
+	client.Hooks.ErrorPolicy = easypost.AbortOnHookErrors
+	client.Hooks.ErrorLogger = recorder
+
+	type contextKey struct{}
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	var bodies []string
+	var errs []error
+	var values []interface{}
+	client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.ResponseHookEvent) error {
+			<-release
+			mutex.Lock()
+			defer mutex.Unlock()
+			bodies = append(bodies, string(event.ResponseBodyBytes))
+			errs = append(errs, ctx.Err())
+			values = append(values, ctx.Value(contextKey{}))
+			return errors.New("slow hook failed")
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "slow"},
+	})
+
+	// the blocked hook does not delay the requests
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "value"))
+	for i := 0; i < 2; i++ {
+		_, err := client.GetAddressWithContext(ctx, "adr_123")
+		// asynchronous hooks can't abort requests
+		require.NoError(err)
+	}
+
+	// hooks run after the request is over keep the values of its context, but not its cancellation
+	cancel()
+	close(release)
+	client.Hooks.Flush()
+	assert.Equal([]string{`{"id": "adr_123"}`, `{"id": "adr_123"}`}, bodies)
+	assert.Equal([]error{nil, nil}, errs)
+	assert.Equal([]interface{}{"value", "value"}, values)
+	assert.Equal(2, len(recorder.messages))
+}
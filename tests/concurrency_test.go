@@ -0,0 +1,159 @@
+package easypost_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+// TestConcurrentRequests shares a single client between goroutines making requests while hooks are added and removed.
+// It is meant to be run with the race detector (go test -race).
+func (c *ClientTests) TestConcurrentRequests() {
+	assert := c.Assert()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(2)
+	client.RateLimiter = easypost.NewRateLimiter(10000, 100)
+	client.Logger = easypost.NewStdLogger(log.New(ioutil.Discard, "", 0))
+	client.Metrics = easypost.NewPrometheusCollector("easypost")
+	client.Tracer = easypost.NewInMemoryTracer()
+
+	var mutex sync.Mutex
+	hookCalls := 0
+	subscriber := func(i int) easypost.RequestHookEventSubscriber {
+		return easypost.RequestHookEventSubscriber{
+			Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+				mutex.Lock()
+				defer mutex.Unlock()
+				hookCalls++
+				return nil
+			},
+			HookEventSubscriber: easypost.HookEventSubscriber{ID: fmt.Sprintf("hook_%d", i), Priority: i % 3},
+		}
+	}
+	client.Hooks.AddRequestEventSubscriber(subscriber(-1))
+
+	const goroutines = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*5)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if _, err := client.GetAddress("adr_123"); err != nil {
+					errs <- err
+				}
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			client.Hooks.AddRequestEventSubscriber(subscriber(i))
+			client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+				Callback: func(ctx context.Context, event easypost.ResponseHookEvent) error {
+					return nil
+				},
+				HookEventSubscriber: easypost.HookEventSubscriber{ID: fmt.Sprintf("hook_%d", i)},
+			})
+			client.Hooks.RemoveRequestEventSubscriber(subscriber(i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(err)
+	}
+	assert.Equal(goroutines*5, len(transport.Requests))
+	// the first subscriber was never removed, so it saw every request
+	assert.True(hookCalls >= goroutines*5)
+	assert.Equal(1, len(client.Hooks.RequestHookEventSubscriptions))
+	assert.Equal(goroutines, len(client.Hooks.ResponseHookEventSubscriptions))
+}
+
+// TestHTTPClientNotModified tests that the HTTP client supplied to a client (or the default one) is not modified.
+func (c *ClientTests) TestHTTPClientNotModified() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	httpClient := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+	client := &easypost.Client{APIKey: "cannot_be_blank", Client: httpClient, Timeout: 1000}
+
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+	assert.Equal(5*time.Second, httpClient.Timeout)
+	assert.Equal(1, len(transport.Requests))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "adr_123"}`))
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL + "/v2/")
+
+	defaultTimeout := http.DefaultClient.Timeout
+	client = &easypost.Client{APIKey: "cannot_be_blank", BaseURL: baseURL, Timeout: 1000}
+	_, err = client.GetAddress("adr_123")
+	require.NoError(err)
+	assert.Equal(defaultTimeout, http.DefaultClient.Timeout)
+}
+
+// TestConcurrentMockRequests shares a single client between goroutines making requests while mock requests are added.
+// It is meant to be run with the race detector (go test -race).
+func (c *ClientTests) TestConcurrentMockRequests() {
+	assert := c.Assert()
+
+	client := easypost.New("cannot_be_blank")
+	mockRequest := func(id string) easypost.MockRequest {
+		return easypost.MockRequest{
+			MatchRule:    easypost.MockRequestMatchRule{Method: http.MethodGet, UrlRegexPattern: "v2\\/addresses\\/" + id + "$"},
+			ResponseInfo: easypost.MockRequestResponseInfo{StatusCode: 200, Body: `{"id": "` + id + `"}`},
+		}
+	}
+	client.SetMockRequests([]easypost.MockRequest{mockRequest("adr_0")})
+
+	const goroutines = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*5)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if _, err := client.GetAddress("adr_0"); err != nil {
+					errs <- err
+				}
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			client.AddMockRequest(mockRequest(fmt.Sprintf("adr_%d", i+1)))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(err)
+	}
+	assert.Equal(goroutines+1, len(client.MockRequests))
+	address, err := client.GetAddress(fmt.Sprintf("adr_%d", goroutines))
+	assert.NoError(err)
+	assert.Equal(fmt.Sprintf("adr_%d", goroutines), address.ID)
+
+	// without mock requests, requests are sent again
+	client.SetMockRequests(nil)
+	assert.Empty(client.MockRequests)
+}
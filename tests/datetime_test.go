@@ -1,7 +1,9 @@
 package easypost_test
 
 import (
+	"encoding/json"
 	"github.com/elmarw/easypost-go/v3"
+	"github.com/google/go-querystring/query"
 	"reflect"
 	"time"
 )
@@ -59,3 +61,153 @@ func (c *ClientTests) TestDateTimeJSON() {
 	assert.NotNil(maxDatetimeString)
 	assert.NotNil(minDatetimeString)
 }
+
+func (c *ClientTests) TestDateTimeQueryEncoding() {
+	assert, require := c.Assert(), c.Require()
+
+	// every format accepted from JSON is sent as the same instant
+	formats := map[string]string{
+		`"2023-01-02"`:                          "2023-01-02T00:00:00Z",
+		`"2023-01-02T03:04:05Z"`:                "2023-01-02T03:04:05Z",
+		`"2023-01-02T03:04:05-07:00"`:           "2023-01-02T03:04:05-07:00",
+		`"2023-01-02T03:04:05.123456789+02:00"`: "2023-01-02T03:04:05.123456789+02:00",
+		`"Mon, 02 Jan 2023 03:04:05 UTC"`:       "2023-01-02T03:04:05Z",
+		`"Mon, 02 Jan 2023 03:04:05 -0700"`:     "2023-01-02T03:04:05-07:00",
+		`"02 Jan 23 03:04 UTC"`:                 "2023-01-02T03:04:00Z",
+		`"02 Jan 23 03:04 -0700"`:               "2023-01-02T03:04:00-07:00",
+		`"Monday, 02-Jan-23 03:04:05 UTC"`:      "2023-01-02T03:04:05Z",
+	}
+	for input, expected := range formats {
+		var datetime easypost.DateTime
+		require.NoError(json.Unmarshal([]byte(input), &datetime), input)
+
+		values, err := query.Values(&easypost.ListOptions{StartDateTime: &datetime})
+		require.NoError(err)
+		assert.Equal(expected, values.Get("start_datetime"), input)
+
+		parsed, err := time.Parse(time.RFC3339Nano, values.Get("start_datetime"))
+		require.NoError(err)
+		assert.True(parsed.Equal(datetime.AsTime()), input)
+	}
+
+	values, err := query.Values(&easypost.ListOptions{})
+	require.NoError(err)
+	assert.Equal("", values.Encode())
+}
+
+func (c *ClientTests) TestListDateFilters() {
+	assert := c.Assert()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	start := easypost.NewDateTime(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := easypost.NewDateTime(2023, time.January, 31, 23, 59, 59, 0, time.FixedZone("EST", -5*3600))
+	opts := &easypost.ListOptions{StartDateTime: &start, EndDateTime: &end}
+	calls := map[string]func() error{
+		"addresses": func() error { _, err := client.ListAddresses(opts); return err },
+		"batches":   func() error { _, err := client.ListBatches(opts); return err },
+		"end_shippers": func() error {
+			_, err := client.ListEndShippers(opts)
+			return err
+		},
+		"events":     func() error { _, err := client.ListEvents(opts); return err },
+		"insurances": func() error { _, err := client.ListInsurances(opts); return err },
+		"pickups":    func() error { _, err := client.ListPickups(opts); return err },
+		"referral_customers": func() error {
+			_, err := client.ListReferralCustomers(opts)
+			return err
+		},
+		"refunds":    func() error { _, err := client.ListRefunds(opts); return err },
+		"reports":    func() error { _, err := client.ListReports("shipment", opts); return err },
+		"scan_forms": func() error { _, err := client.ListScanForms(opts); return err },
+		"shipments": func() error {
+			_, err := client.ListShipments(&easypost.ListShipmentsOptions{StartDateTime: &start, EndDateTime: &end})
+			return err
+		},
+		"trackers": func() error {
+			_, err := client.ListTrackers(&easypost.ListTrackersOptions{StartDateTime: &start, EndDateTime: &end})
+			return err
+		},
+	}
+	for endpoint, call := range calls {
+		transport.Bodies = nil
+		assert.NoError(call(), endpoint)
+		parameters := listParameters(transport.Bodies[0])
+		assert.Equal("2023-01-01T00:00:00Z", parameters.Get("start_datetime"), endpoint)
+		assert.Equal("2023-01-31T23:59:59-05:00", parameters.Get("end_datetime"), endpoint)
+	}
+}
+
+func (c *ClientTests) TestDateTimePrecision() {
+	assert, require := c.Assert(), c.Require()
+
+	// values are marshaled back with the precision they were parsed with
+	formats := map[string]string{
+		`"2023-01-02"`:                    `"2023-01-02"`,
+		`"2023-01-02T03:04:05Z"`:          `"2023-01-02T03:04:05Z"`,
+		`"2023-01-02T03:04:05-07:00"`:     `"2023-01-02T03:04:05-07:00"`,
+		`"2023-01-02T03:04:05.123+02:00"`: `"2023-01-02T03:04:05.123+02:00"`,
+	}
+	for input, expected := range formats {
+		var datetime easypost.DateTime
+		require.NoError(json.Unmarshal([]byte(input), &datetime), input)
+		data, err := json.Marshal(datetime)
+		require.NoError(err)
+		assert.Equal(expected, string(data), input)
+	}
+
+	var tracker easypost.Tracker
+	require.NoError(json.Unmarshal([]byte(`{"est_delivery_date": "2023-01-02"}`), &tracker))
+	assert.True(tracker.EstDeliveryDate.IsDateOnly())
+	assert.Equal("2023-01-02", tracker.EstDeliveryDate.String())
+	assert.Equal(time.Date(2023, time.January, 2, 0, 0, 0, 0, time.UTC), tracker.EstDeliveryDate.AsTime())
+	assert.Equal(easypost.NewDate(2023, time.January, 2), tracker.EstDeliveryDate.Date())
+	data, err := json.Marshal(&tracker)
+	require.NoError(err)
+	assert.Contains(string(data), `"est_delivery_date":"2023-01-02"`)
+
+	// a date has no time zone to convert
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(err)
+	assert.Equal(*tracker.EstDeliveryDate, tracker.EstDeliveryDate.InLocation(newYork))
+
+	timestamp := easypost.NewDateTime(2023, time.January, 2, 3, 4, 5, 0, time.UTC)
+	assert.False(timestamp.IsDateOnly())
+	local := timestamp.InLocation(newYork)
+	assert.True(local.AsTime().Equal(timestamp.AsTime()))
+	assert.Equal(newYork, local.AsTime().Location())
+	assert.Equal(easypost.NewDate(2023, time.January, 1), local.Date())
+
+	assert.True(easypost.DateTime{}.IsZero())
+	assert.False(timestamp.IsZero())
+}
+
+func (c *ClientTests) TestDate() {
+	assert, require := c.Assert(), c.Require()
+
+	date := easypost.NewDate(2023, time.January, 32)
+	assert.Equal(easypost.Date{Year: 2023, Month: time.February, Day: 1}, date)
+	assert.Equal("2023-02-01", date.String())
+	assert.Equal(time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC), date.AsTime())
+	assert.True(date.AsDateTime().IsDateOnly())
+	assert.Equal(date, date.AsDateTime().Date())
+	assert.True(easypost.Date{}.IsZero())
+	assert.False(date.IsZero())
+
+	report := easypost.Report{StartDate: &date}
+	data, err := json.Marshal(&report)
+	require.NoError(err)
+	assert.Equal(`{"start_date":"2023-02-01"}`, string(data))
+
+	// timestamps are read as their date in their own time zone offset
+	var label easypost.PostageLabel
+	require.NoError(json.Unmarshal([]byte(`{"label_date": "2023-02-01T23:30:00-05:00"}`), &label))
+	assert.Equal(date, *label.LabelDate)
+	require.NoError(json.Unmarshal([]byte(`{"label_date": null}`), &label))
+
+	var parsed easypost.Date
+	assert.Error(json.Unmarshal([]byte(`"not a date"`), &parsed))
+}
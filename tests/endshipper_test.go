@@ -76,3 +76,26 @@ func (c *ClientTests) TestEndShipperUpdate() {
 
 	assert.Equal("CAPTAIN SPARROW", updatedEndShipper.Name)
 }
+
+func (c *ClientTests) TestEndShipperGetNextPage() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"endshippers": [{"id": "es_3"}, {"id": "es_2"}], "has_more": true}`},
+			{StatusCode: 200, Body: `{"endshippers": [{"id": "es_1"}], "has_more": false}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	endShippers, err := client.ListEndShippers(&easypost.ListOptions{PageSize: 2})
+	require.NoError(err)
+	nextPage, err := client.GetNextEndShipperPage(endShippers)
+	require.NoError(err)
+	assert.Equal("es_1", nextPage.EndShippers[0].ID)
+	assert.Equal("es_2", listParameters(transport.Bodies[1]).Get("before_id"))
+
+	_, err = client.GetNextEndShipperPageWithPageSize(nextPage, 2)
+	assert.Equal(easypost.EndOfPaginationError, err)
+	assert.Equal(2, len(transport.Requests))
+}
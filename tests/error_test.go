@@ -1,9 +1,18 @@
 package easypost_test
 
 import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/elmarw/easypost-go/v3"
 )
@@ -275,3 +284,314 @@ func (c *ClientTests) TestErrorMessageParseExtreme() {
 		assert.Contains(errorMessage, message)
 	}
 }
+
+// TestTransportErrors tests that errors preventing a request from completing are classified into typed errors,
+// wrapping the original error.
+func (c *ClientTests) TestTransportErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	dnsError := &net.DNSError{Err: "no such host", Name: "api.easypost.com", IsNotFound: true}
+	proxyError := &net.OpError{Op: "proxyconnect", Net: "tcp", Err: errors.New("connection refused")}
+	certificateError := x509.UnknownAuthorityError{}
+	timeoutError := &net.DNSError{Err: "i/o timeout", Name: "api.easypost.com", IsTimeout: true}
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{Err: dnsError},
+			{Err: proxyError},
+			{Err: certificateError},
+			{Err: timeoutError},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	_, err := client.GetAddress("adr_123")
+	var connectionError *easypost.ConnectionError
+	require.True(errors.As(err, &connectionError))
+	assert.Equal(0, connectionError.StatusCode)
+	assert.Contains(connectionError.Error(), "no such host")
+	var unwrappedDNSError *net.DNSError
+	assert.True(errors.As(err, &unwrappedDNSError))
+	assert.Equal(dnsError, unwrappedDNSError)
+
+	_, err = client.GetAddress("adr_123")
+	_, ok := err.(*easypost.ProxyError)
+	assert.True(ok)
+	assert.True(errors.Is(err, proxyError))
+
+	_, err = client.GetAddress("adr_123")
+	_, ok = err.(*easypost.SSLError)
+	assert.True(ok)
+	var unwrappedCertificateError x509.UnknownAuthorityError
+	assert.True(errors.As(err, &unwrappedCertificateError))
+
+	_, err = client.GetAddress("adr_123")
+	_, ok = err.(*easypost.TimeoutError)
+	assert.True(ok)
+
+	// the cause of the embedded APIError is the error returned by the HTTP client
+	var urlError *url.Error
+	assert.True(errors.As(err, &urlError))
+	assert.Equal(urlError, errors.Unwrap(errors.Unwrap(err)))
+}
+
+// TestTransportErrorsFromServer tests the classification of errors produced by the HTTP client against a real server.
+func (c *ClientTests) TestTransportErrorsFromServer() {
+	assert := c.Assert()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"id": "adr_123"}`))
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL + "/v2/")
+
+	// the certificate of the test server is not trusted by the default HTTP client
+	client := &easypost.Client{APIKey: "cannot_be_blank", BaseURL: baseURL, Client: &http.Client{}}
+	_, err := client.GetAddress("adr_123")
+	_, ok := err.(*easypost.SSLError)
+	assert.True(ok)
+
+	// the test server is too slow for the timeout
+	client = &easypost.Client{APIKey: "cannot_be_blank", BaseURL: baseURL, Client: server.Client(), Timeout: 10}
+	_, err = client.GetAddress("adr_123")
+	_, ok = err.(*easypost.TimeoutError)
+	assert.True(ok)
+
+	// the deadline of the context is exceeded
+	client.Timeout = 0
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.GetAddressWithContext(ctx, "adr_123")
+	_, ok = err.(*easypost.TimeoutError)
+	assert.True(ok)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+
+	// a canceled request is not a transport failure
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = client.GetAddressWithContext(ctx, "adr_123")
+	assert.True(errors.Is(err, context.Canceled))
+
+	// nothing listens on the address of the closed server
+	server.Close()
+	_, err = client.GetAddress("adr_123")
+	_, ok = err.(*easypost.ConnectionError)
+	assert.True(ok)
+}
+
+// TestTransportErrorsReadingBody tests the classification of errors occurring while reading the body of a response.
+func (c *ClientTests) TestTransportErrorsReadingBody() {
+	assert := c.Assert()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		_, _ = w.Write([]byte(`{"id": `))
+		w.(http.Flusher).Flush()
+		if r.URL.Path == "/v2/addresses/adr_slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL + "/v2/")
+
+	// the connection is closed before the end of the body
+	client := &easypost.Client{APIKey: "cannot_be_blank", BaseURL: baseURL, Client: server.Client()}
+	_, err := client.GetAddress("adr_123")
+	var connectionError *easypost.ConnectionError
+	assert.True(errors.As(err, &connectionError))
+	assert.True(errors.Is(err, io.ErrUnexpectedEOF))
+
+	// the timeout is exceeded while reading the body
+	client.Timeout = 50
+	_, err = client.GetAddress("adr_slow")
+	_, ok := err.(*easypost.TimeoutError)
+	assert.True(ok)
+
+	// the body is also read by response hooks
+	client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.ResponseHookEvent) error {
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook"},
+	})
+	_, err = client.GetAddress("adr_slow")
+	_, ok = err.(*easypost.TimeoutError)
+	assert.True(ok)
+}
+
+// TestErrorSentinels tests that typed errors can be matched with errors.Is and errors.As through wrapping errors.
+func (c *ClientTests) TestErrorSentinels() {
+	assert := c.Assert()
+
+	res := &http.Response{
+		StatusCode: 404,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"error": {"code": "NOT_FOUND", "message": "The requested resource could not be found."}}`)),
+	}
+	err := fmt.Errorf("retrieving address: %w", easypost.BuildErrorFromResponse(res))
+
+	assert.True(errors.Is(err, easypost.ErrNotFound))
+	assert.True(errors.Is(err, easypost.ErrAPI))
+	assert.False(errors.Is(err, easypost.ErrBadRequest))
+
+	// each typed error unwraps to its APIError
+	var apiError *easypost.APIError
+	assert.True(errors.As(err, &apiError))
+	assert.Equal(404, apiError.StatusCode)
+	assert.Equal("NOT_FOUND", apiError.Code)
+
+	assert.False(errors.Is(easypost.MissingWebhookSignatureError, easypost.ErrAPI))
+}
+
+// TestErrorRetryable tests which errors are reported as retryable and temporary.
+func (c *ClientTests) TestErrorRetryable() {
+	assert := c.Assert()
+
+	type retryableError interface {
+		error
+		Retryable() bool
+		Temporary() bool
+	}
+
+	build := func(statusCode int, body string) retryableError {
+		res := &http.Response{
+			StatusCode: statusCode,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}
+		return easypost.BuildErrorFromResponse(res).(retryableError)
+	}
+
+	for _, statusCode := range []int{408, 429, 502, 503, 504} {
+		err := build(statusCode, "")
+		assert.True(err.Retryable(), statusCode)
+		assert.True(err.Temporary(), statusCode)
+	}
+	for _, statusCode := range []int{400, 401, 402, 404, 422} {
+		err := build(statusCode, "")
+		assert.False(err.Retryable(), statusCode)
+		assert.False(err.Temporary(), statusCode)
+	}
+
+	// a 500 may be transient, but the request may have been processed
+	err := build(500, "")
+	assert.False(err.Retryable())
+	assert.True(err.Temporary())
+
+	// some error codes report transient conditions whatever the status code
+	err = build(422, `{"error": {"code": "SERVICE.UNAVAILABLE", "message": "The service is temporarily unavailable."}}`)
+	assert.True(err.Retryable())
+
+	// the errors of interrupted requests can be checked through errors.As
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{Err: errors.New("connection reset by peer")},
+			{Err: x509.UnknownAuthorityError{}},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	var connectionError *easypost.ConnectionError
+	_, getErr := client.GetAddress("adr_123")
+	assert.True(errors.As(getErr, &connectionError))
+	assert.True(connectionError.Retryable())
+
+	// certificate errors are not retried
+	client.RetryPolicy = fastRetryPolicy(3)
+	var sslError *easypost.SSLError
+	_, getErr = client.GetAddress("adr_123")
+	assert.True(errors.As(getErr, &sslError))
+	assert.False(sslError.Retryable())
+	assert.False(sslError.Temporary())
+	assert.Equal(2, len(transport.Requests))
+}
+
+// TestApiErrorFieldErrors tests that the nested errors relating to fields can be retrieved by field.
+func (c *ClientTests) TestApiErrorFieldErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	fakeErrorResponse := `{
+		"error": {
+			"code": "ADDRESS.VERIFY.FAILURE",
+			"message": "Unable to verify address.",
+			"errors": [
+				{"code": "E.ADDRESS.NOT_FOUND", "field": "address", "message": "Address not found"},
+				{"code": "E.HOUSE_NUMBER.MISSING", "field": "street1", "message": "House number is missing", "suggestion": "Add a house number"},
+				{"field": "street1", "message": ["Too short", "Invalid characters"]},
+				{"message": "Not related to a field", "errors": [{"field": "zip", "message": "Invalid ZIP code"}]}
+			]
+		}
+	}`
+
+	res := &http.Response{
+		StatusCode: 422,
+		Body:       ioutil.NopCloser(strings.NewReader(fakeErrorResponse)),
+	}
+
+	var apiError *easypost.APIError
+	require.True(errors.As(easypost.BuildErrorFromResponse(res), &apiError))
+	assert.Equal(easypost.ErrorCodeAddressVerifyFailure, apiError.ErrorCode())
+
+	fieldErrors := apiError.FieldErrors()
+	assert.Equal(3, len(fieldErrors))
+	assert.Equal(2, len(fieldErrors["street1"]))
+	assert.Equal("Add a house number", fieldErrors["street1"][0].Suggestion)
+	assert.Equal(easypost.ErrorCode("E.HOUSE_NUMBER.MISSING"), fieldErrors["street1"][0].ErrorCode())
+
+	zipError, ok := apiError.FieldError("zip")
+	require.True(ok)
+	assert.Equal("Invalid ZIP code", zipError.Message)
+	_, ok = apiError.FieldError("city")
+	assert.False(ok)
+
+	assert.Equal(map[string]string{
+		"address": "Address not found",
+		"street1": "House number is missing, Too short, Invalid characters",
+		"zip":     "Invalid ZIP code",
+	}, apiError.FieldMessages())
+}
+
+// TestApiErrorRequestDetails tests that errors carry the details of the request that failed, to trace it end-to-end.
+func (c *ClientTests) TestApiErrorRequestDetails() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{
+				StatusCode: 422,
+				Header:     http.Header{"X-Ep-Request-Uuid": []string{"server-uuid"}, "Set-Cookie": []string{"session=secret"}},
+				Body:       `{"error": {"code": "PARAMETER.INVALID", "message": "Invalid credentials.", "errors": []}, "credentials": {"password": "PASSWORD"}}`,
+			},
+			{Err: errors.New("connection reset by peer")},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	var hookIds []string
+	client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+			hookIds = append(hookIds, event.Id.String())
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+
+	_, err := client.UpdateCarrierAccount(&easypost.CarrierAccount{ID: "ca_123"})
+	var apiError *easypost.APIError
+	require.True(errors.As(err, &apiError))
+
+	assert.Equal(http.MethodPatch, apiError.Method)
+	assert.Equal("/v2/carrier_accounts/ca_123", apiError.Path)
+	assert.Equal(hookIds[0], apiError.RequestID.String())
+	assert.Equal("server-uuid", apiError.ServerRequestID)
+	assert.Equal("server-uuid", apiError.Headers.Get("X-Ep-Request-Uuid"))
+	assert.Equal(easypost.DefaultRedactionReplacement, apiError.Headers.Get("Set-Cookie"))
+	assert.Contains(string(apiError.Body), "PARAMETER.INVALID")
+	assert.NotContains(string(apiError.Body), "PASSWORD")
+
+	// errors of requests that did not complete carry the request details too
+	_, err = client.GetAddress("adr_123")
+	require.True(errors.As(err, &apiError))
+	assert.Equal(http.MethodGet, apiError.Method)
+	assert.Equal("/v2/addresses/adr_123", apiError.Path)
+	assert.Equal(hookIds[1], apiError.RequestID.String())
+	assert.Nil(apiError.Body)
+}
@@ -89,8 +89,9 @@ func (fixture *Fixture) ReportType() string {
 	return readFixtureData().ReportTypes["shipment"]
 }
 
-func (fixture *Fixture) ReportDate() string {
-	return "2022-04-11"
+func (fixture *Fixture) ReportDate() *easypost.Date {
+	date := easypost.NewDate(2022, time.April, 11)
+	return &date
 }
 
 func (fixture *Fixture) WebhookUrl() string {
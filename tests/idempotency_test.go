@@ -0,0 +1,81 @@
+package easypost_test
+
+import (
+	"context"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+func (c *ClientTests) TestIdempotencyKeyGeneratedForPurchases() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 503},
+			{StatusCode: 200, Body: `{"id": "shp_123"}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(2)
+
+	var hookKeys []string
+	client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+			hookKeys = append(hookKeys, event.IdempotencyKey)
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+
+	_, err := client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+
+	// purchases with an idempotency key are retried, and the key stays the same across attempts
+	require.Equal(2, len(transport.Requests))
+	key := transport.Requests[0].Header.Get(easypost.IdempotencyKeyHeader)
+	assert.NotEmpty(key)
+	assert.Equal(key, transport.Requests[1].Header.Get(easypost.IdempotencyKeyHeader))
+	assert.Equal([]string{key, key}, hookKeys)
+
+	// every purchase gets its own key
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+	assert.NotEqual(key, transport.Requests[2].Header.Get(easypost.IdempotencyKeyHeader))
+}
+
+func (c *ClientTests) TestIdempotencyKeyFromContext() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "batch_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	ctx := easypost.WithIdempotencyKey(context.Background(), "my-key")
+	key, ok := easypost.IdempotencyKeyFromContext(ctx)
+	assert.True(ok)
+	assert.Equal("my-key", key)
+
+	_, err := client.BuyBatchWithContext(ctx, "batch_123")
+	require.NoError(err)
+	_, err = client.BuyBatchWithContext(ctx, "batch_123")
+	require.NoError(err)
+
+	assert.Equal("my-key", transport.Requests[0].Header.Get(easypost.IdempotencyKeyHeader))
+	assert.Equal("my-key", transport.Requests[1].Header.Get(easypost.IdempotencyKeyHeader))
+}
+
+func (c *ClientTests) TestIdempotencyKeyNotSentForOtherRequests() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "shp_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	ctx := easypost.WithIdempotencyKey(context.Background(), "my-key")
+	_, err := client.CreateShipmentWithContext(ctx, &easypost.Shipment{})
+	require.NoError(err)
+
+	assert.Empty(transport.Requests[0].Header.Get(easypost.IdempotencyKeyHeader))
+}
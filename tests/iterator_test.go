@@ -0,0 +1,181 @@
+package easypost_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+// listParameters returns the parameters of a list request, which are sent form-encoded in its body
+func listParameters(body string) url.Values {
+	values, _ := url.ParseQuery(body)
+	return values
+}
+
+func (c *ClientTests) TestListIterator() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"shipments": [{"id": "shp_5"}, {"id": "shp_4"}], "has_more": true}`},
+			{StatusCode: 200, Body: `{"shipments": [{"id": "shp_3"}, {"id": "shp_2"}], "has_more": true}`},
+			{StatusCode: 200, Body: `{"shipments": [{"id": "shp_1"}], "has_more": false}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	purchased := true
+
+	it := client.IterateShipments(&easypost.ListShipmentsOptions{PageSize: 2, Purchased: &purchased})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Item().(*easypost.Shipment).ID)
+	}
+	require.NoError(it.Err())
+	assert.Equal([]string{"shp_5", "shp_4", "shp_3", "shp_2", "shp_1"}, ids)
+	assert.Equal(5, it.Count())
+	assert.False(it.Next())
+
+	// the options apply to every page
+	require.Equal(3, len(transport.Bodies))
+	assert.Equal("", listParameters(transport.Bodies[0]).Get("before_id"))
+	assert.Equal("shp_4", listParameters(transport.Bodies[1]).Get("before_id"))
+	assert.Equal("shp_2", listParameters(transport.Bodies[2]).Get("before_id"))
+	for _, body := range transport.Bodies {
+		assert.Equal("2", listParameters(body).Get("page_size"))
+		assert.Equal("true", listParameters(body).Get("purchased"))
+	}
+}
+
+func (c *ClientTests) TestListIteratorMaxItemsAndCursor() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"reports": [{"id": "shprep_3"}, {"id": "shprep_2"}], "has_more": true}`},
+			{StatusCode: 200, Body: `{"reports": [{"id": "shprep_2"}, {"id": "shprep_1"}], "has_more": false}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	it := client.IterateReports("shipment", &easypost.ListOptions{PageSize: 2})
+	it.MaxItems = 3
+	require.True(it.Next())
+	assert.Equal("shprep_3", it.Item().(*easypost.Report).ID)
+	cursor, err := it.Cursor()
+	require.NoError(err)
+
+	// the iteration resumes after the last object returned
+	resumed, err := client.ResumeListIteratorWithContext(context.Background(), cursor)
+	require.NoError(err)
+	var ids []string
+	for resumed.Next() {
+		ids = append(ids, resumed.Item().(*easypost.Report).ID)
+	}
+	require.NoError(resumed.Err())
+	assert.Equal([]string{"shprep_2", "shprep_1"}, ids)
+	assert.Equal("/v2/reports/shipment", transport.Requests[1].URL.Path)
+	assert.Equal("shprep_3", listParameters(transport.Bodies[1]).Get("before_id"))
+	assert.Equal("2", listParameters(transport.Bodies[1]).Get("page_size"))
+
+	// MaxItems counts the objects returned before resuming
+	transport.Responses = []ScriptedResponse{
+		{StatusCode: 200, Body: `{"reports": [{"id": "shprep_2"}, {"id": "shprep_1"}], "has_more": false}`},
+	}
+	transport.Requests = nil
+	resumed, err = client.ResumeListIterator(cursor)
+	require.NoError(err)
+	resumed.MaxItems = 2
+	ids = nil
+	for resumed.Next() {
+		ids = append(ids, resumed.Item().(*easypost.Report).ID)
+	}
+	assert.Equal([]string{"shprep_2"}, ids)
+
+	// a finished iteration stays finished
+	cursor, err = resumed.Cursor()
+	require.NoError(err)
+	resumed, err = client.ResumeListIterator(cursor)
+	require.NoError(err)
+	assert.False(resumed.Next())
+	assert.Equal(1, len(transport.Requests))
+
+	_, err = client.ResumeListIterator("not a cursor")
+	var invalidErr *easypost.InvalidObjectError
+	assert.True(errors.As(err, &invalidErr))
+}
+
+func (c *ClientTests) TestListIteratorErrors() {
+	assert := c.Assert()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"trackers": [{"id": "trk_2"}], "has_more": true}`},
+			{StatusCode: 404, Body: `{"error": {"code": "NOT_FOUND", "message": "not found"}}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	it := client.IterateTrackers(nil)
+	assert.True(it.Next())
+	assert.False(it.Next())
+	assert.True(errors.Is(it.Err(), easypost.ErrNotFound))
+	assert.Nil(it.Item())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it = client.IterateTrackersWithContext(ctx, nil)
+	assert.False(it.Next())
+	assert.Equal(context.Canceled, it.Err())
+	assert.Equal(2, len(transport.Requests))
+}
+
+func (c *ClientTests) TestListIteratorEndpoints() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{
+			"addresses": [{"id": "adr_1"}], "batches": [{"id": "batch_1"}], "endshippers": [{"id": "es_1"}], "insurances": [{"id": "ins_1"}],
+			"pickups": [{"id": "pickup_1"}], "referral_customers": [{"id": "user_1"}], "refunds": [{"id": "rfnd_1"}],
+			"scan_forms": [{"id": "sf_1"}], "shipments": [{"id": "shp_1"}], "trackers": [{"id": "trk_1"}],
+			"reports": [{"id": "shprep_1"}]
+		}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	iterators := map[string]*easypost.ListIterator{
+		"/v2/addresses":           client.IterateAddresses(nil),
+		"/v2/batches":             client.IterateBatches(nil),
+		"/v2/end_shippers":        client.IterateEndShippers(nil),
+		"/v2/insurances":          client.IterateInsurances(nil),
+		"/v2/pickups":             client.IteratePickups(nil),
+		"/v2/referral_customers":  client.IterateReferralCustomers(nil),
+		"/v2/refunds":             client.IterateRefunds(nil),
+		"/v2/scan_forms":          client.IterateScanForms(nil),
+		"/v2/shipments":           client.IterateShipments(nil),
+		"/v2/trackers":            client.IterateTrackers(nil),
+		"/v2/reports/payment_log": client.IterateReports("payment_log", nil),
+	}
+	for path, it := range iterators {
+		transport.Requests = nil
+		require.True(it.Next(), path)
+		assert.NotNil(it.Item(), path)
+		if path == "/v2/referral_customers" {
+			_, ok := it.Item().(*easypost.ReferralCustomer)
+			assert.True(ok)
+		}
+		assert.False(it.Next(), path)
+		assert.NoError(it.Err(), path)
+		require.Equal(1, len(transport.Requests), path)
+		assert.Equal(path, transport.Requests[0].URL.Path)
+	}
+
+	// the response has no events
+	transport.Requests = nil
+	it := client.IterateEvents(nil)
+	assert.False(it.Next())
+	assert.NoError(it.Err())
+	require.Equal(1, len(transport.Requests))
+	assert.Equal("/v2/events", transport.Requests[0].URL.Path)
+}
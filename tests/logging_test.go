@@ -0,0 +1,185 @@
+package easypost_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+// logRecord is a log record captured by a recordingLogger
+type logRecord struct {
+	level   easypost.LogLevel
+	message string
+	fields  map[string]interface{}
+}
+
+// recordingLogger returns a Logger appending the records it receives to records
+func recordingLogger(records *[]logRecord) easypost.Logger {
+	return easypost.LoggerFunc(func(ctx context.Context, level easypost.LogLevel, message string, fields []easypost.LogField) {
+		record := logRecord{level: level, message: message, fields: map[string]interface{}{}}
+		for _, field := range fields {
+			record.fields[field.Key] = field.Value
+		}
+		*records = append(*records, record)
+	})
+}
+
+func (c *ClientTests) TestLoggingSuccessfulCall() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 503},
+			{StatusCode: 200, Body: `{"id": "adr_123"}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(2)
+
+	var records []logRecord
+	client.Logger = recordingLogger(&records)
+
+	var hookId string
+	client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.ResponseHookEvent) error {
+			hookId = event.Id.String()
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	// a single record is emitted for all attempts
+	require.Equal(1, len(records))
+	record := records[0]
+	assert.Equal(easypost.LogLevelInfo, record.level)
+	assert.Equal("GET", record.fields[easypost.LogFieldMethod])
+	assert.Equal("/v2/addresses/adr_123", record.fields[easypost.LogFieldPath])
+	assert.Equal(200, record.fields[easypost.LogFieldStatus])
+	assert.Equal(2, record.fields[easypost.LogFieldAttempts])
+	assert.Equal(hookId, record.fields[easypost.LogFieldRequestID])
+	assert.True(record.fields[easypost.LogFieldDuration].(time.Duration) >= 0)
+	assert.NotContains(record.fields, easypost.LogFieldErrorCode)
+	assert.NotContains(record.fields, easypost.LogFieldRequestBody)
+}
+
+func (c *ClientTests) TestLoggingFailedCall() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 404, Body: `{"error": {"code": "NOT_FOUND", "message": "The requested resource could not be found."}}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	var records []logRecord
+	client.Logger = recordingLogger(&records)
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+
+	require.Equal(1, len(records))
+	record := records[0]
+	assert.Equal(easypost.LogLevelWarn, record.level)
+	assert.Equal(404, record.fields[easypost.LogFieldStatus])
+	assert.Equal("NOT_FOUND", record.fields[easypost.LogFieldErrorCode])
+	assert.Equal(err.Error(), record.fields[easypost.LogFieldError])
+
+	// records below the minimum level are dropped
+	records = nil
+	client.LogLevel = easypost.LogLevelError
+	_, err = client.GetAddress("adr_123")
+	require.Error(err)
+	assert.Empty(records)
+}
+
+func (c *ClientTests) TestLoggingDebugBodies() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"id": "ca_123", "credentials": {"password": "PASSWORD"}}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	var records []logRecord
+	client.Logger = recordingLogger(&records)
+	client.LogLevel = easypost.LogLevelDebug
+
+	carrierAccount, err := client.CreateCarrierAccount(&easypost.CarrierAccount{
+		Type:        "UpsAccount",
+		Credentials: map[string]string{"password": "PASSWORD"},
+	})
+	require.NoError(err)
+	assert.Equal("ca_123", carrierAccount.ID)
+
+	require.Equal(1, len(records))
+	requestBody := string(records[0].fields[easypost.LogFieldRequestBody].([]byte))
+	responseBody := string(records[0].fields[easypost.LogFieldResponseBody].([]byte))
+	assert.Contains(requestBody, "UpsAccount")
+	assert.NotContains(requestBody, "PASSWORD")
+	assert.Contains(responseBody, "ca_123")
+	assert.NotContains(responseBody, "PASSWORD")
+}
+
+// keyValueRecorder implements easypost.KeyValueLogger
+type keyValueRecorder struct {
+	method        string
+	keysAndValues []interface{}
+}
+
+func (r *keyValueRecorder) Debugw(message string, keysAndValues ...interface{}) {
+	r.method, r.keysAndValues = "debug", keysAndValues
+}
+
+func (r *keyValueRecorder) Infow(message string, keysAndValues ...interface{}) {
+	r.method, r.keysAndValues = "info", keysAndValues
+}
+
+func (r *keyValueRecorder) Warnw(message string, keysAndValues ...interface{}) {
+	r.method, r.keysAndValues = "warn", keysAndValues
+}
+
+func (r *keyValueRecorder) Errorw(message string, keysAndValues ...interface{}) {
+	r.method, r.keysAndValues = "error", keysAndValues
+}
+
+// kitRecorder implements easypost.KitLogger
+type kitRecorder struct {
+	keyvals []interface{}
+}
+
+func (r *kitRecorder) Log(keyvals ...interface{}) error {
+	r.keyvals = keyvals
+	return nil
+}
+
+func (c *ClientTests) TestLoggerAdapters() {
+	assert := c.Assert()
+
+	fields := []easypost.LogField{
+		{Key: "method", Value: "GET"},
+		{Key: "status", Value: 404},
+		{Key: "error", Value: "NOT_FOUND The requested resource could not be found."},
+	}
+
+	var buffer bytes.Buffer
+	easypost.NewStdLogger(log.New(&buffer, "", 0)).Log(context.Background(), easypost.LogLevelWarn, "easypost api call", fields)
+	assert.Equal("level=warn msg=\"easypost api call\" method=GET status=404 error=\"NOT_FOUND The requested resource could not be found.\"\n", buffer.String())
+
+	keyValue := &keyValueRecorder{}
+	easypost.NewKeyValueLogger(keyValue).Log(context.Background(), easypost.LogLevelWarn, "easypost api call", fields)
+	assert.Equal("warn", keyValue.method)
+	assert.Equal([]interface{}{"method", "GET", "status", 404, "error", "NOT_FOUND The requested resource could not be found."}, keyValue.keysAndValues)
+
+	kit := &kitRecorder{}
+	easypost.NewKitLogger(kit).Log(context.Background(), easypost.LogLevelError, "easypost api call", fields[:1])
+	assert.Equal([]interface{}{"level", "error", "msg", "easypost api call", "method", "GET"}, kit.keyvals)
+}
@@ -0,0 +1,109 @@
+package easypost_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+// metricsRecorder implements easypost.MetricsCollector
+type metricsRecorder struct {
+	calls     []easypost.CallMetrics
+	purchases []easypost.LabelPurchase
+}
+
+func (r *metricsRecorder) ObserveCall(call easypost.CallMetrics) {
+	r.calls = append(r.calls, call)
+}
+
+func (r *metricsRecorder) ObserveLabelPurchase(purchase easypost.LabelPurchase) {
+	r.purchases = append(r.purchases, purchase)
+}
+
+func (c *ClientTests) TestMetricsCalls() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"id": "shp_123", "selected_rate": {"carrier": "USPS", "service": "Priority", "rate": "7.50", "currency": "USD"}}`},
+			{StatusCode: 404, Body: `{"error": {"code": "NOT_FOUND", "message": "The requested resource could not be found."}}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	metrics := &metricsRecorder{}
+	client.Metrics = metrics
+
+	// retrieving a bought shipment is not a purchase
+	_, err := client.GetShipment("shp_123")
+	require.NoError(err)
+	_, err = client.GetShipment("shp_456")
+	require.Error(err)
+
+	require.Equal(2, len(metrics.calls))
+	assert.Equal("shipments/{id}", metrics.calls[0].Endpoint)
+	assert.Equal(http.MethodGet, metrics.calls[0].Method)
+	assert.Equal(200, metrics.calls[0].StatusCode)
+	assert.Equal("", metrics.calls[0].ErrorType)
+	assert.Equal(1, metrics.calls[0].Attempts)
+	assert.Equal(404, metrics.calls[1].StatusCode)
+	assert.Equal("NotFoundError", metrics.calls[1].ErrorType)
+	assert.Empty(metrics.purchases)
+}
+
+func (c *ClientTests) TestMetricsLabelPurchases() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"id": "shp_123", "selected_rate": {"carrier": "USPS", "service": "Priority", "rate": "7.50", "currency": "USD"}, "fees": [{"type": "LabelFee", "amount": "0.01"}, {"type": "PostageFee", "amount": "7.25"}]}`},
+			{StatusCode: 200, Body: `{"id": "order_123", "shipments": [{"selected_rate": {"carrier": "UPS", "service": "Ground", "rate": "10.00", "currency": "USD"}}, {"selected_rate": {"carrier": "UPS", "service": "Ground", "rate": "12.00", "currency": "USD"}}]}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	metrics := &metricsRecorder{}
+	client.Metrics = metrics
+
+	_, err := client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+	_, err = client.BuyOrder("order_123", "UPS", "Ground")
+	require.NoError(err)
+
+	require.Equal(3, len(metrics.purchases))
+	// the postage fee takes precedence over the rate
+	assert.Equal(easypost.LabelPurchase{Carrier: "USPS", Service: "Priority", Currency: "USD", Postage: 7.25}, metrics.purchases[0])
+	assert.Equal(easypost.LabelPurchase{Carrier: "UPS", Service: "Ground", Currency: "USD", Postage: 10}, metrics.purchases[1])
+	assert.Equal(12.0, metrics.purchases[2].Postage)
+	assert.Equal("shipments/{id}/buy", metrics.calls[0].Endpoint)
+	assert.Equal("orders/{id}/buy", metrics.calls[1].Endpoint)
+}
+
+func (c *ClientTests) TestPrometheusCollector() {
+	assert := c.Assert()
+
+	collector := easypost.NewPrometheusCollector("easypost")
+	collector.ObserveCall(easypost.CallMetrics{Endpoint: "shipments/{id}", Method: "GET", StatusCode: 200, Duration: 200 * time.Millisecond, Attempts: 1})
+	collector.ObserveCall(easypost.CallMetrics{Endpoint: "shipments/{id}", Method: "GET", StatusCode: 404, ErrorType: "NotFoundError", Duration: 2 * time.Second, Attempts: 1})
+	collector.ObserveLabelPurchase(easypost.LabelPurchase{Carrier: "USPS", Service: "Priority", Currency: "USD", Postage: 7.25})
+	collector.ObserveLabelPurchase(easypost.LabelPurchase{Carrier: "USPS", Service: "Priority", Currency: "USD", Postage: 2.5})
+
+	recorder := httptest.NewRecorder()
+	collector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.True(strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+	body := recorder.Body.String()
+	assert.Contains(body, "# TYPE easypost_requests_total counter\n")
+	assert.Contains(body, `easypost_requests_total{endpoint="shipments/{id}",method="GET",status="200"} 1`+"\n")
+	assert.Contains(body, `easypost_requests_total{endpoint="shipments/{id}",method="GET",status="404"} 1`+"\n")
+	assert.Contains(body, "# TYPE easypost_request_duration_seconds histogram\n")
+	assert.Contains(body, `easypost_request_duration_seconds_bucket{endpoint="shipments/{id}",method="GET",le="0.1"} 0`+"\n")
+	assert.Contains(body, `easypost_request_duration_seconds_bucket{endpoint="shipments/{id}",method="GET",le="0.25"} 1`+"\n")
+	assert.Contains(body, `easypost_request_duration_seconds_bucket{endpoint="shipments/{id}",method="GET",le="+Inf"} 2`+"\n")
+	assert.Contains(body, `easypost_request_duration_seconds_sum{endpoint="shipments/{id}",method="GET"} 2.2`+"\n")
+	assert.Contains(body, `easypost_request_duration_seconds_count{endpoint="shipments/{id}",method="GET"} 2`+"\n")
+	assert.Contains(body, `easypost_errors_total{endpoint="shipments/{id}",method="GET",error_type="NotFoundError"} 1`+"\n")
+	assert.Contains(body, `easypost_labels_purchased_total{carrier="USPS",service="Priority"} 2`+"\n")
+	assert.Contains(body, `easypost_postage_spent_total{currency="USD"} 9.75`+"\n")
+}
@@ -0,0 +1,132 @@
+package easypost_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+func (c *ClientTests) TestMiddlewareOrder() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	var calls []string
+	tracingMiddleware := func(name string) easypost.Middleware {
+		return func(next easypost.Handler) easypost.Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, "before "+name)
+				res, err := next(req)
+				calls = append(calls, "after "+name)
+				return res, err
+			}
+		}
+	}
+	client.Use(tracingMiddleware("first"), tracingMiddleware("second"))
+
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal([]string{"before first", "before second", "after second", "after first"}, calls)
+}
+
+func (c *ClientTests) TestMiddlewareModifiesRequest() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.Use(easypost.HeaderMiddleware(http.Header{"X-Custom-Header": []string{"custom"}}))
+
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal("custom", transport.Requests[0].Header.Get("X-Custom-Header"))
+}
+
+func (c *ClientTests) TestMiddlewareVetoesRequest() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "shp_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	errPurchasesDisabled := errors.New("purchases are disabled")
+	client.Use(func(next easypost.Handler) easypost.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/buy") {
+				return nil, errPurchasesDisabled
+			}
+			return next(req)
+		}
+	})
+
+	_, err := client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	assert.Equal(errPurchasesDisabled, err)
+	assert.Equal(0, len(transport.Requests))
+
+	_, err = client.GetShipment("shp_123")
+	require.NoError(err)
+	assert.Equal(1, len(transport.Requests))
+}
+
+func (c *ClientTests) TestMiddlewareShortCircuitsResponse() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 500}},
+	}
+	client := c.ScriptedClient(transport)
+
+	// serve a canned response, as a caching middleware would
+	client.Use(func(next easypost.Handler) easypost.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"id": "adr_cached"}`)),
+			}, nil
+		}
+	})
+
+	address, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal("adr_cached", address.ID)
+	assert.Equal(0, len(transport.Requests))
+}
+
+func (c *ClientTests) TestMiddlewareSeesErrorResponses() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 404, Body: `{"error": {"code": "NOT_FOUND", "message": "not found"}}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	statusCode := 0
+	client.Use(func(next easypost.Handler) easypost.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			res, err := next(req)
+			if err == nil {
+				statusCode = res.StatusCode
+			}
+			return res, err
+		}
+	})
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+
+	_, ok := err.(*easypost.NotFoundError)
+	assert.True(ok)
+	assert.Equal(404, statusCode)
+}
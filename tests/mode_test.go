@@ -0,0 +1,178 @@
+package easypost_test
+
+import (
+	"errors"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+func (c *ClientTests) TestVerifyMode() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{
+			"id": "user_123",
+			"keys": [{"object": "ApiKey", "mode": "production", "key": "EZAK_PRODUCTION"}],
+			"children": [{"id": "user_456", "keys": [{"object": "ApiKey", "mode": "test", "key": "cannot_be_blank"}]}]
+		}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.ExpectedMode = easypost.ModeTest
+	require.NoError(client.VerifyMode())
+
+	client.ExpectedMode = easypost.ModeProduction
+	err := client.VerifyMode()
+	var modeErr *easypost.ModeMismatchError
+	require.True(errors.As(err, &modeErr))
+	assert.Equal(easypost.ModeProduction, modeErr.ExpectedMode)
+	assert.Equal(easypost.ModeTest, modeErr.ActualMode)
+
+	// purchases are refused without sending a request
+	requests := len(transport.Requests)
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	assert.True(errors.As(err, &modeErr))
+	_, err = client.BuyBatch("batch_123")
+	assert.True(errors.As(err, &modeErr))
+	assert.Equal(requests, len(transport.Requests))
+
+	// a key missing from the list cannot be verified
+	client.APIKey = "EZAK_UNKNOWN"
+	err = client.VerifyMode()
+	require.Error(err)
+	assert.False(errors.As(err, &modeErr))
+}
+
+func (c *ClientTests) TestModeFromResponses() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "shp_123", "mode": "test"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.ExpectedMode = easypost.ModeProduction
+
+	_, err := client.GetShipment("shp_123")
+	require.NoError(err)
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	var modeErr *easypost.ModeMismatchError
+	require.True(errors.As(err, &modeErr))
+	assert.Equal(easypost.ModeTest, modeErr.ActualMode)
+	assert.Equal(1, len(transport.Requests))
+
+	// other keys are not affected, their mode is verified before their first purchase
+	client.APIKey = "EZAK_OTHER"
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	require.Error(err)
+	assert.False(errors.As(err, &modeErr))
+	require.Equal(2, len(transport.Requests))
+	assert.Equal("/v2/api_keys", transport.Requests[1].URL.Path)
+}
+
+func (c *ClientTests) TestModeVerifiedBeforeFirstPurchase() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{
+			"id": "user_123",
+			"keys": [{"object": "ApiKey", "mode": "production", "key": "cannot_be_blank"}]
+		}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.ExpectedMode = easypost.ModeTest
+
+	// the first purchase is refused without being sent
+	_, err := client.BuyBatch("batch_123")
+	var modeErr *easypost.ModeMismatchError
+	require.True(errors.As(err, &modeErr))
+	assert.Equal(easypost.ModeProduction, modeErr.ActualMode)
+	require.Equal(1, len(transport.Requests))
+	assert.Equal("/v2/api_keys", transport.Requests[0].URL.Path)
+
+	// the mode is only verified once
+	_, err = client.BuyBatch("batch_123")
+	assert.True(errors.As(err, &modeErr))
+	assert.Equal(1, len(transport.Requests))
+
+	// a key whose mode cannot be verified cannot make purchases
+	transport.Responses = []ScriptedResponse{{StatusCode: 500}}
+	client.APIKey = "EZAK_OTHER"
+	_, err = client.BuyBatch("batch_123")
+	require.Error(err)
+	for _, req := range transport.Requests {
+		assert.NotEqual("/v2/batches/batch_123/buy", req.URL.Path)
+	}
+}
+
+func (c *ClientTests) TestModeOfPurchasedObjects() {
+	assert := c.Assert()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"keys": [{"object": "ApiKey", "mode": "production", "key": "cannot_be_blank"}]}`},
+			{StatusCode: 200, Body: `{"id": "shp_123"}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.ExpectedMode = easypost.ModeProduction
+
+	_, err := client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123", Mode: easypost.ModeTest}, "")
+	var modeErr *easypost.ModeMismatchError
+	assert.True(errors.As(err, &modeErr))
+	// only the mode of the key was verified
+	assert.Equal(1, len(transport.Requests))
+
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123", Mode: easypost.ModeProduction}, "")
+	assert.NoError(err)
+
+	// without an expected mode, nothing is checked
+	client.ExpectedMode = ""
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123", Mode: easypost.ModeTest}, "")
+	assert.NoError(err)
+}
+
+func (c *ClientTests) TestModeOfFallbackKey() {
+	assert, require := c.Assert(), c.Require()
+
+	keys := func(fallbackMode string) string {
+		return `{"keys": [{"object": "ApiKey", "mode": "test", "key": "cannot_be_blank"}, {"object": "ApiKey", "mode": "` + fallbackMode + `", "key": "EZAK_FALLBACK"}]}`
+	}
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: keys(easypost.ModeProduction)},
+			{StatusCode: 401, Body: `{"error": {"code": "APIKEY.INACTIVE", "message": "inactive"}}`},
+			{StatusCode: 200, Body: keys(easypost.ModeProduction)},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.ExpectedMode = easypost.ModeTest
+	client.FallbackAPIKeyProvider = easypost.StaticAPIKey("EZAK_FALLBACK")
+
+	// the purchase is not sent again with a fallback key of another mode
+	_, err := client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	var modeErr *easypost.ModeMismatchError
+	require.True(errors.As(err, &modeErr))
+	assert.Equal(easypost.ModeProduction, modeErr.ActualMode)
+	require.Equal(3, len(transport.Requests))
+	fallbackKey, _, _ := transport.Requests[2].BasicAuth()
+	assert.Equal("EZAK_FALLBACK", fallbackKey)
+	assert.Equal("/v2/api_keys", transport.Requests[2].URL.Path)
+
+	// it is with a fallback key of the expected mode
+	transport = &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: keys(easypost.ModeTest)},
+			{StatusCode: 401, Body: `{"error": {"code": "APIKEY.INACTIVE", "message": "inactive"}}`},
+			{StatusCode: 200, Body: keys(easypost.ModeTest)},
+			{StatusCode: 200, Body: `{"id": "shp_123"}`},
+		},
+	}
+	client = c.ScriptedClient(transport)
+	client.ExpectedMode = easypost.ModeTest
+	client.FallbackAPIKeyProvider = easypost.StaticAPIKey("EZAK_FALLBACK")
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+	require.Equal(4, len(transport.Requests))
+	fallbackKey, _, _ = transport.Requests[3].BasicAuth()
+	assert.Equal("EZAK_FALLBACK", fallbackKey)
+	assert.Equal("/v2/shipments/shp_123/buy", transport.Requests[3].URL.Path)
+}
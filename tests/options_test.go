@@ -0,0 +1,112 @@
+package easypost_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+func (c *ClientTests) TestNewWithOptions() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	baseURL, _ := url.Parse("https://example.com/api/")
+	hookCalled := false
+	logger := easypost.NewStdLogger(log.New(ioutil.Discard, "", 0))
+
+	client := easypost.New("EZTK123",
+		easypost.WithBaseURL(baseURL),
+		easypost.WithTimeout(5*time.Second),
+		easypost.WithHTTPClient(&http.Client{Transport: transport}),
+		easypost.WithUserAgentSuffix("Warehouse/1.0"),
+		easypost.WithRetryPolicy(fastRetryPolicy(2)),
+		easypost.WithLogger(logger, easypost.LogLevelWarn),
+		easypost.WithRequestHook(easypost.RequestHookEventSubscriber{
+			Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+				hookCalled = true
+				return nil
+			},
+			HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+		}),
+	)
+
+	assert.Equal("EZTK123", client.APIKey)
+	assert.Equal(5000, client.Timeout)
+	assert.Equal(2, client.RetryPolicy.MaxAttempts)
+	assert.Equal(logger, client.Logger)
+	assert.Equal(easypost.LogLevelWarn, client.LogLevel)
+
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	require.Equal(1, len(transport.Requests))
+	request := transport.Requests[0]
+	assert.Equal("https://example.com/api/addresses/adr_123", request.URL.String())
+	assert.True(strings.HasPrefix(request.Header.Get("User-Agent"), "EasyPost/v2 GoClient/"))
+	assert.True(strings.HasSuffix(request.Header.Get("User-Agent"), " Warehouse/1.0"))
+	assert.True(hookCalled)
+}
+
+func (c *ClientTests) TestCallOptions() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "shp_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	ctx := easypost.WithCallOptions(context.Background(), easypost.CallHeader("X-Request-Source", "warehouse-1"))
+	// options are added to those already in the context
+	ctx = easypost.WithCallOptions(ctx,
+		easypost.CallAPIKey("EZAK_CUSTOMER"),
+		easypost.CallIdempotencyKey("order-123"),
+	)
+
+	_, err := client.BuyShipmentWithContext(ctx, "shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+
+	request := transport.Requests[0]
+	assert.Equal("warehouse-1", request.Header.Get("X-Request-Source"))
+	assert.Equal("order-123", request.Header.Get(easypost.IdempotencyKeyHeader))
+	assert.Equal("Basic "+base64.StdEncoding.EncodeToString([]byte("EZAK_CUSTOMER:")), request.Header.Get("Authorization"))
+
+	// calls without options are unaffected
+	_, err = client.GetShipment("shp_123")
+	require.NoError(err)
+	request = transport.Requests[1]
+	assert.Equal("", request.Header.Get("X-Request-Source"))
+	assert.Equal("Basic "+base64.StdEncoding.EncodeToString([]byte("cannot_be_blank:")), request.Header.Get("Authorization"))
+
+	// an alternate API key can be used by a client without one
+	client.APIKey = ""
+	_, err = client.GetShipmentWithContext(easypost.WithCallOptions(context.Background(), easypost.CallAPIKey("EZAK_CUSTOMER")), "shp_123")
+	require.NoError(err)
+}
+
+func (c *ClientTests) TestCallTimeout() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 503}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = &easypost.RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Hour}
+
+	start := time.Now()
+	ctx := easypost.WithCallOptions(context.Background(), easypost.CallTimeout(10*time.Millisecond))
+	_, err := client.GetShipmentWithContext(ctx, "shp_123")
+	require.Error(err)
+
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.True(time.Since(start) < time.Minute)
+	assert.Equal(1, len(transport.Requests))
+}
@@ -0,0 +1,129 @@
+package easypost_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+func (c *ClientTests) TestRateLimiterThrottlesRequests() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RateLimiter = easypost.NewRateLimiter(50, 1)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := client.GetAddress("adr_123")
+		require.NoError(err)
+	}
+
+	// the first request uses the burst, the following three wait 20ms each
+	assert.True(time.Since(start) >= 55*time.Millisecond)
+	assert.Equal(4, len(transport.Requests))
+}
+
+func (c *ClientTests) TestRateLimiterSharedAcrossGoroutines() {
+	assert := c.Assert()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	limiter := easypost.NewRateLimiter(100, 2)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := c.ScriptedClient(transport)
+			client.RateLimiter = limiter
+			_, err := client.GetAddress("adr_123")
+			assert.NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	// two requests use the burst, the following four wait 10ms each
+	assert.True(time.Since(start) >= 35*time.Millisecond)
+	assert.Equal(6, len(transport.Requests))
+}
+
+func (c *ClientTests) TestRateLimiterRespectsContext() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RateLimiter = easypost.NewRateLimiter(0.001, 1)
+
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = client.GetAddressWithContext(ctx, "adr_123")
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.Equal(1, len(transport.Requests))
+}
+
+func (c *ClientTests) TestRateLimiterEndpointLimits() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "trk_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RateLimiter = easypost.NewRateLimiter(0.001, 1)
+	client.RateLimiter.SetEndpointLimit("trackers", 0, 0)
+
+	// the trackers endpoint is unlimited, and doesn't consume the global limit
+	for i := 0; i < 3; i++ {
+		_, err := client.GetTracker("trk_123")
+		require.NoError(err)
+	}
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = client.GetShipmentWithContext(ctx, "shp_123")
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.Equal(4, len(transport.Requests))
+}
+
+func (c *ClientTests) TestRateLimiterSlowsDownOnRateLimitError() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 429},
+			{StatusCode: 200, Body: `{"id": "shp_123"}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.RateLimiter = easypost.NewRateLimiter(1000, 10)
+	client.RateLimiter.SetEndpointLimit("shipments", 1000, 10)
+
+	_, err := client.GetShipment("shp_123")
+	require.Error(err)
+	_, ok := err.(*easypost.RateLimitError)
+	require.True(ok)
+
+	assert.Equal(500.0, client.RateLimiter.CurrentRate("shipments"))
+	assert.Equal(1000.0, client.RateLimiter.CurrentRate("addresses"))
+
+	// successful requests gradually restore the rate
+	_, err = client.GetShipment("shp_123")
+	require.NoError(err)
+	assert.Equal(550.0, client.RateLimiter.CurrentRate("shipments"))
+}
@@ -0,0 +1,114 @@
+package easypost_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+func (c *ClientTests) TestRedactionInHooks() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "ca_123", "credentials": {"password": "PASSWORD"}}`}},
+	}
+	client := c.ScriptedClient(transport)
+
+	var requestEvent easypost.RequestHookEvent
+	var responseEvent easypost.ResponseHookEvent
+	client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+			requestEvent = event
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+	client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.ResponseHookEvent) error {
+			responseEvent = event
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+
+	carrierAccount, err := client.CreateCarrierAccount(&easypost.CarrierAccount{
+		Type:        "UpsAccount",
+		Description: "My UPS account",
+		Credentials: map[string]string{"user_id": "USERID", "password": "PASSWORD"},
+	})
+	require.NoError(err)
+
+	// the request and response themselves are untouched
+	assert.Contains(transport.Requests[0].Header.Get("Authorization"), "Basic ")
+	assert.Contains(transport.Bodies[0], "PASSWORD")
+	assert.Equal("ca_123", carrierAccount.ID)
+
+	// hooks only see redacted values
+	assert.Equal([]string{easypost.DefaultRedactionReplacement}, requestEvent.Headers["Authorization"])
+	assert.NotContains(string(requestEvent.RequestBodyBytes), "PASSWORD")
+	assert.Contains(string(requestEvent.RequestBodyBytes), "My UPS account")
+	assert.NotContains(string(responseEvent.ResponseBodyBytes), "PASSWORD")
+	assert.Contains(string(responseEvent.ResponseBodyBytes), "ca_123")
+}
+
+func (c *ClientTests) TestRedactionCustomRedactor() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "adr_123", "phone": "415-123-4567"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.Redactor = &easypost.Redactor{
+		Headers:     []string{"user-agent"},
+		Fields:      []string{"phone"},
+		Replacement: "***",
+	}
+
+	var requestEvent easypost.RequestHookEvent
+	client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+			requestEvent = event
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+
+	_, err := client.CreateAddress(&easypost.Address{Phone: "415-123-4567", Zip: "94104"}, nil)
+	require.NoError(err)
+
+	assert.Equal([]string{"***"}, requestEvent.Headers["User-Agent"])
+	// the default redactor has been replaced, so the API key is visible
+	assert.Contains(requestEvent.Headers["Authorization"][0], "Basic ")
+	assert.Contains(string(requestEvent.RequestBodyBytes), `"phone":"***"`)
+	assert.Contains(string(requestEvent.RequestBodyBytes), `"zip":"94104"`)
+}
+
+func (c *ClientTests) TestRedactorBodies() {
+	assert := c.Assert()
+
+	redactor := easypost.DefaultRedactor()
+
+	// nested JSON fields are redacted at any depth, numbers are preserved
+	jsonBody := `{"user": {"api_keys": [{"key": "EZAK123", "mode": "production"}]}, "amount": 12345678901234567890}`
+	redacted := string(redactor.RedactBody([]byte(jsonBody)))
+	assert.NotContains(redacted, "EZAK123")
+	assert.Contains(redacted, "production")
+	assert.Contains(redacted, "12345678901234567890")
+
+	// form fields are matched on their innermost name
+	formBody := "card%5Bnumber%5D=4242424242424242&card%5Bexp_month%5D=12"
+	redacted = string(redactor.RedactBody([]byte(formBody)))
+	assert.NotContains(redacted, "4242424242424242")
+	assert.Contains(redacted, "12")
+
+	// bodies without secrets are returned unchanged
+	unchanged := `{"id":  "adr_123"}`
+	assert.Equal(unchanged, string(redactor.RedactBody([]byte(unchanged))))
+
+	header := http.Header{"Authorization": []string{"Basic abc"}, "Content-Type": []string{"application/json"}}
+	redactedHeader := redactor.RedactHeaders(header)
+	assert.Equal("Basic abc", header.Get("Authorization"))
+	assert.Equal(easypost.DefaultRedactionReplacement, redactedHeader.Get("Authorization"))
+	assert.Equal("application/json", redactedHeader.Get("Content-Type"))
+}
@@ -0,0 +1,198 @@
+package easypost_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+	"github.com/google/uuid"
+)
+
+// fastRetryPolicy returns a retry policy with negligible delays so tests run quickly
+func fastRetryPolicy(maxAttempts int) *easypost.RetryPolicy {
+	return &easypost.RetryPolicy{
+		MaxAttempts: maxAttempts,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+}
+
+func (c *ClientTests) TestRetryTransientErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 503},
+			{StatusCode: 429},
+			{StatusCode: 200, Body: `{"addresses": [], "has_more": false}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(3)
+
+	var attempts []int
+	var ids []uuid.UUID
+	client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+			attempts = append(attempts, event.Attempt)
+			ids = append(ids, event.Id)
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+
+	_, err := client.ListAddresses(&easypost.ListOptions{PageSize: 5})
+	require.NoError(err)
+
+	assert.Equal(3, len(transport.Requests))
+	assert.Equal([]int{1, 2, 3}, attempts)
+	assert.Equal(ids[0], ids[1])
+	assert.Equal(ids[0], ids[2])
+
+	// the request body must be replayed identically on every attempt
+	assert.Equal(transport.Bodies[0], transport.Bodies[1])
+	assert.Equal(transport.Bodies[0], transport.Bodies[2])
+	assert.NotEmpty(transport.Bodies[0])
+}
+
+func (c *ClientTests) TestRetryGivesUpAfterMaxAttempts() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 429}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(4)
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+
+	_, ok := err.(*easypost.RateLimitError)
+	assert.True(ok)
+	assert.Equal(4, len(transport.Requests))
+}
+
+func (c *ClientTests) TestRetryTransportErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{Err: errors.New("connection reset by peer")},
+			{StatusCode: 200, Body: `{"id": "adr_123"}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(2)
+
+	address, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal("adr_123", address.ID)
+	assert.Equal(2, len(transport.Requests))
+}
+
+func (c *ClientTests) TestRetrySkipsNonIdempotentRequests() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 503}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(3)
+
+	_, err := client.CreateAddress(&easypost.Address{Street1: "417 Montgomery Street"}, nil)
+	require.Error(err)
+
+	_, ok := err.(*easypost.ServiceUnavailableError)
+	assert.True(ok)
+	assert.Equal(1, len(transport.Requests))
+}
+
+func (c *ClientTests) TestRetrySkipsNonTransientErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 404}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(3)
+
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+
+	_, ok := err.(*easypost.NotFoundError)
+	assert.True(ok)
+	assert.Equal(1, len(transport.Requests))
+}
+
+func (c *ClientTests) TestRetryRetryableErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 408},
+			{StatusCode: 500, Body: `{"error": {"code": "SERVICE.UNAVAILABLE", "message": "try again"}}`},
+			{StatusCode: 500, Body: `{"error": {"code": "INTERNAL_SERVER_ERROR", "message": "oops"}}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = fastRetryPolicy(5)
+
+	// requests are retried whenever their error is retryable, and the body of the last response is still decoded
+	_, err := client.GetAddress("adr_123")
+	require.Error(err)
+	assert.Equal(3, len(transport.Requests))
+	var apiError *easypost.InternalServerError
+	require.True(errors.As(err, &apiError))
+	assert.Equal("INTERNAL_SERVER_ERROR", apiError.Code)
+	assert.False(apiError.Retryable())
+}
+
+func (c *ClientTests) TestRetryRespectsRetryAfter() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 429, Header: http.Header{"Retry-After": []string{"0"}}},
+			{StatusCode: 200, Body: `{"id": "adr_123"}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	// the computed backoff would make the test time out; Retry-After overrides it
+	client.RetryPolicy = &easypost.RetryPolicy{
+		MaxAttempts:       2,
+		BaseBackoff:       time.Hour,
+		RespectRetryAfter: true,
+	}
+
+	start := time.Now()
+	_, err := client.GetAddress("adr_123")
+	require.NoError(err)
+
+	assert.Equal(2, len(transport.Requests))
+	assert.True(time.Since(start) < time.Minute)
+}
+
+func (c *ClientTests) TestRetryStopsWhenContextIsDone() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 503}},
+	}
+	client := c.ScriptedClient(transport)
+	client.RetryPolicy = &easypost.RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: time.Hour,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetAddressWithContext(ctx, "adr_123")
+	require.Error(err)
+
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.Equal(1, len(transport.Requests))
+}
@@ -0,0 +1,164 @@
+package easypost_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+func (c *ClientTests) TestListShipmentsInWindows() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"shipments": [{"id": "shp_9"}, {"id": "shp_8"}], "has_more": true}`},
+			{StatusCode: 200, Body: `{"shipments": [{"id": "shp_7"}], "has_more": false}`},
+			{StatusCode: 200, Body: `{"shipments": [{"id": "shp_7"}, {"id": "shp_6"}], "has_more": false}`},
+			{StatusCode: 200, Body: `{"shipments": [{"id": "shp_5"}], "has_more": false}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	start := easypost.NewDateTime(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := easypost.NewDateTime(2023, time.January, 4, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	err := client.ListShipmentsInWindows(&easypost.ShipmentWindowOptions{
+		StartDateTime: &start,
+		EndDateTime:   &end,
+		Concurrency:   1,
+		PageSize:      2,
+	}, func(shipment *easypost.Shipment) error {
+		ids = append(ids, shipment.ID)
+		return nil
+	})
+	require.NoError(err)
+	// the shipment at the edge of the first two windows is only listed once
+	assert.Equal([]string{"shp_9", "shp_8", "shp_7", "shp_6", "shp_5"}, ids)
+	assert.Equal(4, len(transport.Requests))
+
+	// windows are listed newest first, and pages keep the dates of their window
+	windows := [][2]string{
+		{"2023-01-03T00:00:00Z", "2023-01-04T00:00:00Z"},
+		{"2023-01-03T00:00:00Z", "2023-01-04T00:00:00Z"},
+		{"2023-01-02T00:00:00Z", "2023-01-03T00:00:00Z"},
+		{"2023-01-01T00:00:00Z", "2023-01-02T00:00:00Z"},
+	}
+	for i, window := range windows {
+		parameters := listParameters(transport.Bodies[i])
+		assert.Equal(window[0], parameters.Get("start_datetime"))
+		assert.Equal(window[1], parameters.Get("end_datetime"))
+	}
+
+	// listing stops at the first error
+	stop := errors.New("stop")
+	err = client.ListShipmentsInWindows(&easypost.ShipmentWindowOptions{StartDateTime: &start, EndDateTime: &end}, func(shipment *easypost.Shipment) error {
+		return stop
+	})
+	assert.Equal(stop, err)
+
+	err = client.ListShipmentsInWindows(&easypost.ShipmentWindowOptions{StartDateTime: &start}, func(shipment *easypost.Shipment) error {
+		return nil
+	})
+	var missingErr *easypost.MissingPropertyError
+	assert.True(errors.As(err, &missingErr))
+}
+
+func (c *ClientTests) TestListShipmentsInWindowsFilters() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"shipments": [{"id": "shp_2"}], "has_more": true}`},
+			{StatusCode: 200, Body: `{"shipments": [{"id": "shp_1"}], "has_more": false}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+
+	zone := time.FixedZone("UTC-5", -5*60*60)
+	start := easypost.NewDateTime(2023, time.January, 1, 8, 30, 0, 250000000, zone)
+	end := easypost.NewDateTime(2023, time.January, 1, 20, 30, 0, 250000000, zone)
+	purchased, includeChildren := true, false
+	err := client.ListShipmentsInWindows(&easypost.ShipmentWindowOptions{
+		StartDateTime:   &start,
+		EndDateTime:     &end,
+		PageSize:        1,
+		Purchased:       &purchased,
+		IncludeChildren: &includeChildren,
+	}, func(shipment *easypost.Shipment) error {
+		return nil
+	})
+	require.NoError(err)
+	require.Equal(2, len(transport.Requests))
+
+	// every page of the window sends its dates, keeping their offset and fractional seconds, and the other filters
+	for i, beforeID := range []string{"", "shp_2"} {
+		parameters := listParameters(transport.Bodies[i])
+		assert.Equal("2023-01-01T08:30:00.25-05:00", parameters.Get("start_datetime"))
+		assert.Equal("2023-01-01T20:30:00.25-05:00", parameters.Get("end_datetime"))
+		assert.Equal("1", parameters.Get("page_size"))
+		assert.Equal("true", parameters.Get("purchased"))
+		assert.Equal("false", parameters.Get("include_children"))
+		assert.Equal(beforeID, parameters.Get("before_id"))
+	}
+}
+
+func (c *ClientTests) TestListShipmentsInWindowsConcurrency() {
+	assert, require := c.Assert(), c.Require()
+
+	var requests, inFlight, maxInFlight int32
+	var mutex sync.Mutex
+	windows := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		parameters := listParameters(string(body))
+		mutex.Lock()
+		windows[parameters.Get("start_datetime")] = parameters.Get("end_datetime")
+		mutex.Unlock()
+
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		id := atomic.AddInt32(&requests, 1)
+		_, _ = fmt.Fprintf(w, `{"shipments": [{"id": "shp_%d"}], "has_more": false}`, id)
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL + "/v2/")
+	client := easypost.New("cannot_be_blank", easypost.WithBaseURL(baseURL))
+
+	start := easypost.NewDateTime(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := easypost.NewDateTime(2023, time.January, 11, 0, 0, 0, 0, time.UTC)
+	count := 0
+	err := client.ListShipmentsInWindows(&easypost.ShipmentWindowOptions{
+		StartDateTime: &start,
+		EndDateTime:   &end,
+		Concurrency:   3,
+	}, func(shipment *easypost.Shipment) error {
+		count++
+		return nil
+	})
+	require.NoError(err)
+	assert.Equal(10, count)
+	assert.Equal(int32(10), atomic.LoadInt32(&requests))
+	assert.True(atomic.LoadInt32(&maxInFlight) <= 3)
+
+	// each request lists the shipments of its own window
+	require.Equal(10, len(windows))
+	for day := 1; day <= 10; day++ {
+		windowStart := time.Date(2023, time.January, day, 0, 0, 0, 0, time.UTC)
+		assert.Equal(windowStart.AddDate(0, 0, 1).Format(time.RFC3339), windows[windowStart.Format(time.RFC3339)])
+	}
+}
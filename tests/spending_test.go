@@ -0,0 +1,274 @@
+package easypost_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+// spendingRuleOf returns the rule of a SpendingPolicyError, or an empty rule if err is not one
+func spendingRuleOf(err error) easypost.SpendingRule {
+	var policyErr *easypost.SpendingPolicyError
+	if errors.As(err, &policyErr) {
+		return policyErr.Rule
+	}
+	return ""
+}
+
+func (c *ClientTests) TestSpendingPolicyLimits() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "shp_123"}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.SpendingPolicy = &easypost.SpendingPolicy{
+		MaxRate:         20,
+		MaxInsurance:    100,
+		AllowedServices: map[string][]string{"USPS": {"Priority", "GroundAdvantage"}, "UPS": nil},
+	}
+
+	rate := &easypost.Rate{ID: "rate_123", Carrier: "USPS", Service: "Priority", Rate: "7.58"}
+	_, err := client.BuyShipment("shp_123", rate, "50.00")
+	require.NoError(err)
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123", Carrier: "UPS", Service: "NextDayAir", Rate: "19.99"}, "")
+	require.NoError(err)
+	require.Equal(2, len(transport.Requests))
+
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123", Carrier: "USPS", Service: "Priority", Rate: "20.01"}, "")
+	assert.Equal(easypost.SpendingRuleMaxRate, spendingRuleOf(err))
+	_, err = client.BuyShipmentWithCarbonOffset("shp_123", rate, "100.01")
+	assert.Equal(easypost.SpendingRuleMaxInsurance, spendingRuleOf(err))
+	_, err = client.InsureShipment("shp_123", "250")
+	assert.Equal(easypost.SpendingRuleMaxInsurance, spendingRuleOf(err))
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123", Carrier: "FedEx", Service: "FEDEX_GROUND", Rate: "9.00"}, "")
+	assert.Equal(easypost.SpendingRuleAllowedServices, spendingRuleOf(err))
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123", Carrier: "USPS", Service: "Express", Rate: "9.00"}, "")
+	assert.Equal(easypost.SpendingRuleAllowedServices, spendingRuleOf(err))
+	_, err = client.BuyShipment("shp_123", &easypost.Rate{ID: "rate_123", Carrier: "USPS", Service: "Priority"}, "")
+	assert.Equal(easypost.SpendingRuleUnknownRate, spendingRuleOf(err))
+	_, err = client.BuyOrder("order_123", "FedEx", "FEDEX_GROUND")
+	assert.Equal(easypost.SpendingRuleAllowedServices, spendingRuleOf(err))
+	_, err = client.BuyPickup("pickup_123", &easypost.PickupRate{Carrier: "USPS", Service: "NextDay", Rate: "0.00"})
+	assert.Equal(easypost.SpendingRuleAllowedServices, spendingRuleOf(err))
+	priority := []*easypost.Rate{{Carrier: "USPS", Service: "Priority", Rate: "7.58"}}
+	_, err = client.CreateAndBuyBatch(&easypost.Shipment{Carrier: "USPS", Service: "Priority", Rates: priority}, &easypost.Shipment{Carrier: "DHL"})
+	assert.Equal(easypost.SpendingRuleAllowedServices, spendingRuleOf(err))
+
+	// the rates of batch shipments are taken from their rates, and are needed to enforce MaxRate
+	_, err = client.CreateAndBuyBatch(&easypost.Shipment{Carrier: "USPS", Service: "Priority", Rates: []*easypost.Rate{{Carrier: "USPS", Service: "Priority", Rate: "25.00"}}})
+	assert.Equal(easypost.SpendingRuleMaxRate, spendingRuleOf(err))
+	_, err = client.CreateAndBuyBatch(&easypost.Shipment{Carrier: "USPS", Service: "Priority"})
+	assert.Equal(easypost.SpendingRuleUnknownRate, spendingRuleOf(err))
+
+	// no request is sent for refused purchases
+	assert.Equal(2, len(transport.Requests))
+}
+
+func (c *ClientTests) TestSpendingPolicyDailyLimit() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"id": "shp_1", "selected_rate": {"carrier": "USPS", "service": "Priority", "rate": "8.00"}, "fees": [{"type": "PostageFee", "amount": "7.50"}]}`},
+			{StatusCode: 200, Body: `{"id": "order_1", "shipments": [{"id": "shp_2", "rates": [{"carrier": "USPS", "service": "Priority", "rate": "2.50"}]}]}`},
+			{StatusCode: 200, Body: `{"id": "order_1", "shipments": [{"id": "shp_2", "selected_rate": {"carrier": "USPS", "service": "Priority", "rate": "2.50"}}]}`},
+		},
+	}
+	store := easypost.NewInMemorySpendingStore()
+	client := c.ScriptedClient(transport)
+	client.SpendingPolicy = &easypost.SpendingPolicy{DailyLimit: 10, Store: store}
+
+	// the spend is tracked from the postage actually paid
+	_, err := client.BuyShipment("shp_1", &easypost.Rate{ID: "rate_1", Rate: "8.00"}, "")
+	require.NoError(err)
+	_, err = client.BuyShipment("shp_1", &easypost.Rate{ID: "rate_1", Rate: "2.51"}, "")
+	assert.Equal(easypost.SpendingRuleDailyLimit, spendingRuleOf(err))
+	require.Equal(1, len(transport.Requests))
+
+	// orders are priced with their rates before being bought
+	_, err = client.BuyOrder("order_1", "USPS", "Priority")
+	require.NoError(err)
+	require.Equal(3, len(transport.Requests))
+	assert.Equal("/v2/orders/order_1", transport.Requests[1].URL.Path)
+	_, err = client.BuyOrder("order_1", "USPS", "Priority")
+	assert.Equal(easypost.SpendingRuleDailyLimit, spendingRuleOf(err))
+
+	// batches are refused once the limit has been reached
+	_, err = client.BuyBatch("batch_1")
+	assert.Equal(easypost.SpendingRuleDailyLimit, spendingRuleOf(err))
+	for _, req := range transport.Requests[3:] {
+		assert.Equal(http.MethodGet, req.Method)
+	}
+
+	spent, err := store.Spent(context.Background(), time.Now().UTC().Format("2006-01-02"))
+	require.NoError(err)
+	assert.InDelta(10.0, spent, 0.001)
+}
+
+func (c *ClientTests) TestSpendingPolicyOrderRates() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "order_1", "shipments": [
+			{"id": "shp_1", "rates": [{"carrier": "USPS", "service": "Priority", "rate": "12.00"}, {"carrier": "USPS", "service": "Express", "rate": "30.00"}]},
+			{"id": "shp_2", "rates": [{"carrier": "USPS", "service": "Priority", "rate": "9.00"}, {"carrier": "USPS", "service": "Express", "rate": "26.00"}]}
+		]}`}},
+	}
+	client := c.ScriptedClient(transport)
+	client.SpendingPolicy = &easypost.SpendingPolicy{MaxRate: 20, DailyLimit: 100}
+
+	// each label of the order is checked against MaxRate
+	_, err := client.BuyOrder("order_1", "USPS", "Express")
+	assert.Equal(easypost.SpendingRuleMaxRate, spendingRuleOf(err))
+	_, err = client.BuyOrder("order_1", "USPS", "ParcelSelect")
+	assert.Equal(easypost.SpendingRuleUnknownRate, spendingRuleOf(err))
+	for _, req := range transport.Requests {
+		assert.Equal(http.MethodGet, req.Method)
+	}
+
+	_, err = client.BuyOrder("order_1", "USPS", "Priority")
+	require.NoError(err)
+	assert.Equal(http.MethodPost, transport.Requests[len(transport.Requests)-1].Method)
+}
+
+func (c *ClientTests) TestSpendingPolicyReservations() {
+	assert, require := c.Assert(), c.Require()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"id": "shp_1", "selected_rate": {"carrier": "USPS", "service": "Priority", "rate": "1.00"}}`))
+	}))
+	defer server.Close()
+	baseURL, _ := url.Parse(server.URL + "/v2/")
+	store := easypost.NewInMemorySpendingStore()
+	client := easypost.New("cannot_be_blank", easypost.WithBaseURL(baseURL), easypost.WithSpendingPolicy(&easypost.SpendingPolicy{DailyLimit: 5, Store: store}))
+
+	// concurrent purchases cannot exceed the daily limit together
+	var bought int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.BuyShipment("shp_1", &easypost.Rate{ID: "rate_1", Rate: "1.00"}, ""); err == nil {
+				atomic.AddInt32(&bought, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(int32(5), atomic.LoadInt32(&bought))
+	today := time.Now().UTC().Format("2006-01-02")
+	spent, err := store.Spent(context.Background(), today)
+	require.NoError(err)
+	assert.InDelta(5.0, spent, 0.001)
+
+	// the reservation of a failed purchase is released
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 500, Body: `{"error": {"code": "INTERNAL_SERVER_ERROR", "message": "oops"}}`}},
+	}
+	failing := c.ScriptedClient(transport)
+	failing.SpendingPolicy = &easypost.SpendingPolicy{DailyLimit: 5}
+	_, err = failing.BuyShipment("shp_1", &easypost.Rate{ID: "rate_1", Rate: "4.00"}, "")
+	require.Error(err)
+	transport.Responses = []ScriptedResponse{{StatusCode: 200, Body: `{"id": "shp_1"}`}}
+	_, err = failing.BuyShipment("shp_1", &easypost.Rate{ID: "rate_1", Rate: "4.00"}, "")
+	assert.NoError(err)
+}
+
+func (c *ClientTests) TestSpendingPolicyUncertainPurchases() {
+	assert, require := c.Assert(), c.Require()
+
+	today := time.Now().UTC().Format("2006-01-02")
+	newClient := func(transport *ScriptedRoundTripper) (*easypost.Client, easypost.SpendingStore) {
+		store := easypost.NewInMemorySpendingStore()
+		client := c.ScriptedClient(transport)
+		client.RetryPolicy = nil
+		client.SpendingPolicy = &easypost.SpendingPolicy{DailyLimit: 5, Store: store}
+		return client, store
+	}
+
+	// the purchase may have been made when the connection fails after sending it: the reservation is kept
+	client, store := newClient(&ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{Err: errors.New("connection reset by peer")}},
+	})
+	_, err := client.BuyShipment("shp_1", &easypost.Rate{ID: "rate_1", Rate: "4.00"}, "")
+	require.Error(err)
+	spent, err := store.Spent(context.Background(), today)
+	require.NoError(err)
+	assert.InDelta(4.0, spent, 0.001)
+
+	// as when a response hook aborts a successful purchase
+	client, store = newClient(&ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "shp_1"}`}},
+	})
+	client.Hooks.ErrorPolicy = easypost.AbortOnHookErrors
+	client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.ResponseHookEvent) error {
+			return errors.New("response vetoed")
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "veto"},
+	})
+	_, err = client.BuyShipment("shp_1", &easypost.Rate{ID: "rate_1", Rate: "4.00"}, "")
+	require.Error(err)
+	spent, err = store.Spent(context.Background(), today)
+	require.NoError(err)
+	assert.InDelta(4.0, spent, 0.001)
+
+	// the reservation is released when a request hook aborts the purchase before it is sent
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 200, Body: `{"id": "shp_1"}`}},
+	}
+	client, store = newClient(transport)
+	client.Hooks.ErrorPolicy = easypost.AbortOnHookErrors
+	client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+			return errors.New("request vetoed")
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "veto"},
+	})
+	_, err = client.BuyShipment("shp_1", &easypost.Rate{ID: "rate_1", Rate: "4.00"}, "")
+	require.Error(err)
+	assert.Empty(transport.Requests)
+	spent, err = store.Spent(context.Background(), today)
+	require.NoError(err)
+	assert.Zero(spent)
+
+	// and when the caller's context is done once the API rejected the purchase
+	client, store = newClient(&ScriptedRoundTripper{
+		Responses: []ScriptedResponse{{StatusCode: 422, Body: `{"error": {"code": "SHIPMENT.POSTAGE.FAILURE", "message": "oops"}}`}},
+	})
+	client.SpendingPolicy.Store = contextSpendingStore{store}
+	ctx, cancel := context.WithCancel(context.Background())
+	client.Hooks.AddResponseEventSubscriber(easypost.ResponseHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.ResponseHookEvent) error {
+			cancel()
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "cancel"},
+	})
+	_, err = client.BuyShipmentWithContext(ctx, "shp_1", &easypost.Rate{ID: "rate_1", Rate: "4.00"}, "")
+	require.Error(err)
+	spent, err = store.Spent(context.Background(), today)
+	require.NoError(err)
+	assert.Zero(spent)
+}
+
+// contextSpendingStore is a SpendingStore failing to update the spend once the given context is done.
+type contextSpendingStore struct {
+	easypost.SpendingStore
+}
+
+func (s contextSpendingStore) AddSpent(ctx context.Context, day string, amount float64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.SpendingStore.AddSpent(ctx, day, amount)
+}
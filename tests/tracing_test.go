@@ -0,0 +1,84 @@
+package easypost_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/elmarw/easypost-go/v3"
+)
+
+func (c *ClientTests) TestTracingSpans() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 200, Body: `{"id": "shp_123"}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	tracer := easypost.NewInMemoryTracer()
+	client.Tracer = tracer
+
+	var hookId string
+	var hookCtx context.Context
+	client.Hooks.AddRequestEventSubscriber(easypost.RequestHookEventSubscriber{
+		Callback: func(ctx context.Context, event easypost.RequestHookEvent) error {
+			hookId = event.Id.String()
+			hookCtx = ctx
+			return nil
+		},
+		HookEventSubscriber: easypost.HookEventSubscriber{ID: "hook_1"},
+	})
+
+	// the span of the call is a child of the span in the caller's context
+	ctx, parent := tracer.Start(context.Background(), "fulfill order")
+	_, err := client.BuyShipmentWithContext(ctx, "shp_123", &easypost.Rate{ID: "rate_123"}, "")
+	require.NoError(err)
+	parent.End()
+
+	spans := tracer.Spans()
+	require.Equal(2, len(spans))
+	span := spans[1]
+	assert.Equal("POST shipments/{id}/buy", span.Name)
+	assert.Equal(spans[0].ID, span.ParentID)
+	assert.Equal(http.MethodPost, span.Attributes[easypost.SpanAttributeMethod])
+	assert.Equal("shipments/{id}/buy", span.Attributes[easypost.SpanAttributeEndpoint])
+	assert.Equal(200, span.Attributes[easypost.SpanAttributeStatusCode])
+	assert.Equal(hookId, span.Attributes[easypost.SpanAttributeRequestID])
+	assert.Equal(1, span.Attributes[easypost.SpanAttributeAttempts])
+	assert.NotContains(span.Attributes, easypost.SpanAttributeErrorCode)
+	assert.Nil(span.Err)
+	assert.False(span.EndTime.Before(span.StartTime))
+
+	// hooks receive the context carrying the span, so they can start child spans
+	_, child := tracer.Start(hookCtx, "child")
+	child.End()
+	assert.Equal(span.ID, tracer.Spans()[2].ParentID)
+}
+
+func (c *ClientTests) TestTracingErrors() {
+	assert, require := c.Assert(), c.Require()
+
+	transport := &ScriptedRoundTripper{
+		Responses: []ScriptedResponse{
+			{StatusCode: 422, Body: `{"error": {"code": "TRACKER.CREATE.ERROR", "message": "Invalid tracking code"}}`},
+		},
+	}
+	client := c.ScriptedClient(transport)
+	tracer := easypost.NewInMemoryTracer()
+	client.Tracer = tracer
+
+	_, err := client.GetTracker("trk_123")
+	require.Error(err)
+
+	spans := tracer.Spans()
+	require.Equal(1, len(spans))
+	assert.Equal("GET trackers/{id}", spans[0].Name)
+	assert.Equal(0, spans[0].ParentID)
+	assert.Equal(422, spans[0].Attributes[easypost.SpanAttributeStatusCode])
+	assert.Equal("TRACKER.CREATE.ERROR", spans[0].Attributes[easypost.SpanAttributeErrorCode])
+	assert.Equal(err, spans[0].Err)
+
+	tracer.Reset()
+	assert.Empty(tracer.Spans())
+}
@@ -0,0 +1,197 @@
+package easypost
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Attributes set on the span of an API call.
+const (
+	SpanAttributeMethod     = "http.method"
+	SpanAttributeStatusCode = "http.status_code"
+	SpanAttributeEndpoint   = "easypost.endpoint"
+	SpanAttributeErrorCode  = "easypost.error_code"
+	SpanAttributeRequestID  = "easypost.request_id"
+	SpanAttributeAttempts   = "easypost.attempts"
+)
+
+// Tracer starts the spans of the API calls made by a Client (via its Tracer property). It can be implemented on top
+// of any tracing library, such as OpenTelemetry; see InMemoryTracer for an implementation suited to tests.
+type Tracer interface {
+	// Start starts a span with the given name, as a child of the span in ctx if any, and returns a context carrying
+	// the new span. The returned context is used for the request, so it is visible to middlewares, hooks and the
+	// HTTP transport.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is an operation started by a Tracer.
+type Span interface {
+	// SetAttribute sets an attribute of the span, replacing any previous value with the same key.
+	SetAttribute(key string, value interface{})
+	// RecordError marks the span as failed with the given error.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// startSpan starts the span of an API call, if the Client has a Tracer. The returned span is nil otherwise.
+func (c *Client) startSpan(req *http.Request) (context.Context, Span) {
+	if c.Tracer == nil {
+		return req.Context(), nil
+	}
+	endpoint := c.endpointTemplateOf(req)
+	ctx, span := c.Tracer.Start(req.Context(), req.Method+" "+endpoint)
+	span.SetAttribute(SpanAttributeMethod, req.Method)
+	span.SetAttribute(SpanAttributeEndpoint, endpoint)
+	return ctx, span
+}
+
+// endSpan sets the outcome of an API call on its span and ends it.
+func endSpan(span Span, call *callInfo, res *http.Response, err error) {
+	if span == nil {
+		return
+	}
+	status, errorCode := callStatus(res, err)
+	span.SetAttribute(SpanAttributeRequestID, call.id.String())
+	span.SetAttribute(SpanAttributeAttempts, call.attempts)
+	if status != 0 {
+		span.SetAttribute(SpanAttributeStatusCode, status)
+	}
+	if errorCode != "" {
+		span.SetAttribute(SpanAttributeErrorCode, errorCode)
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// endpointTemplateOf returns the path of a request relative to the base URL, with object IDs replaced by "{id}"
+// (e.g. "shipments/{id}/buy"), so that calls to the same endpoint share the same template.
+func (c *Client) endpointTemplateOf(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, c.baseURL().Path)
+	path = strings.Trim(path, "/")
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		// endpoint names never contain digits, while object IDs (e.g. "shp_...") and tracking codes always do
+		if strings.IndexFunc(segment, unicode.IsDigit) >= 0 {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// RecordedSpan is a span recorded by an InMemoryTracer.
+type RecordedSpan struct {
+	// ID identifies the span within its InMemoryTracer, starting from 1.
+	ID int
+	// ParentID is the ID of the parent span, or 0 if the span has no parent.
+	ParentID   int
+	Name       string
+	Attributes map[string]interface{}
+	Err        error
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// InMemoryTracer is a Tracer keeping the spans in memory, to test tracing. It is safe for concurrent use.
+type InMemoryTracer struct {
+	mutex  sync.Mutex
+	nextID int
+	spans  []*inMemorySpan
+}
+
+// inMemorySpan is a Span of an InMemoryTracer.
+type inMemorySpan struct {
+	tracer *InMemoryTracer
+	span   RecordedSpan
+	ended  bool
+}
+
+type inMemorySpanContextKey struct{}
+
+// NewInMemoryTracer returns a new, empty InMemoryTracer.
+func NewInMemoryTracer() *InMemoryTracer {
+	return &InMemoryTracer{}
+}
+
+// Start starts a span, as a child of the span of this tracer in ctx, if any.
+func (t *InMemoryTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.nextID++
+	span := &inMemorySpan{
+		tracer: t,
+		span: RecordedSpan{
+			ID:         t.nextID,
+			Name:       name,
+			Attributes: make(map[string]interface{}),
+			StartTime:  time.Now(),
+		},
+	}
+	if parent, ok := ctx.Value(inMemorySpanContextKey{}).(*inMemorySpan); ok && parent.tracer == t {
+		span.span.ParentID = parent.span.ID
+	}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, inMemorySpanContextKey{}, span), span
+}
+
+// Spans returns a copy of the spans that have ended, in the order they were started.
+func (t *InMemoryTracer) Spans() []RecordedSpan {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	spans := make([]RecordedSpan, 0, len(t.spans))
+	for _, span := range t.spans {
+		if !span.ended {
+			continue
+		}
+		recorded := span.span
+		recorded.Attributes = make(map[string]interface{}, len(span.span.Attributes))
+		for key, value := range span.span.Attributes {
+			recorded.Attributes[key] = value
+		}
+		spans = append(spans, recorded)
+	}
+	return spans
+}
+
+// Reset removes all the spans recorded so far.
+func (t *InMemoryTracer) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.spans = nil
+}
+
+// SetAttribute sets an attribute of the span.
+func (s *inMemorySpan) SetAttribute(key string, value interface{}) {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+
+	s.span.Attributes[key] = value
+}
+
+// RecordError records the error of the span.
+func (s *inMemorySpan) RecordError(err error) {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+
+	s.span.Err = err
+}
+
+// End ends the span; it is ignored if the span has already ended.
+func (s *inMemorySpan) End() {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+
+	if !s.ended {
+		s.ended = true
+		s.span.EndTime = time.Now()
+	}
+}
@@ -187,6 +187,9 @@ func (c *Client) ListTrackers(opts *ListTrackersOptions) (out *ListTrackersResul
 // allows specifying a context that can interrupt the request.
 func (c *Client) ListTrackersWithContext(ctx context.Context, opts *ListTrackersOptions) (out *ListTrackersResult, err error) {
 	err = c.do(ctx, http.MethodGet, "trackers", c.convertOptsToURLValues(opts), &out)
+	if err != nil {
+		return
+	}
 	// Store the original query parameters for reuse when getting the next page
 	out.TrackingCode = opts.TrackingCode
 	out.Carrier = opts.Carrier
@@ -243,3 +246,36 @@ func (c *Client) GetTrackerWithContext(ctx context.Context, trackerID string) (o
 	err = c.get(ctx, "trackers/"+trackerID, &out)
 	return
 }
+
+// IterateTrackers returns a ListIterator over the trackers matching the given options, whose items are of type
+// *Tracker.
+func (c *Client) IterateTrackers(opts *ListTrackersOptions) *ListIterator {
+	return c.IterateTrackersWithContext(context.Background(), opts)
+}
+
+// IterateTrackersWithContext performs the same operation as IterateTrackers, but allows specifying a context that
+// can interrupt the requests of the iterator.
+func (c *Client) IterateTrackersWithContext(ctx context.Context, opts *ListTrackersOptions) *ListIterator {
+	options := ListTrackersOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	return c.newListIterator(ctx, "trackers", &options)
+}
+
+// listTrackersPage fetches a page of trackers for a ListIterator.
+func listTrackersPage(ctx context.Context, c *Client, options interface{}, beforeID string) (*listPage, error) {
+	opts := *options.(*ListTrackersOptions)
+	if beforeID != "" {
+		opts.BeforeID = beforeID
+	}
+	result, err := c.ListTrackersWithContext(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+	page := &listPage{hasMore: result.HasMore}
+	for _, tracker := range result.Trackers {
+		page.add(tracker, tracker.ID)
+	}
+	return page, nil
+}